@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v2"
+)
+
+var unmarshalFuncs = map[string]i18n.UnmarshalFunc{
+	"toml": toml.Unmarshal,
+	"yaml": yaml.Unmarshal,
+	"yml":  yaml.Unmarshal,
+}
+
+// catalog is messages of a language, which may be split into files
+type catalog struct {
+	Tag      language.Tag
+	Files    []string
+	Messages map[string]*i18n.Message
+}
+
+// loadCatalogs loads message files of dir like messages.LoadDir, and returns catalogs sorted by language
+func loadCatalogs(dir string) ([]*catalog, error) {
+	byTag := map[language.Tag]*catalog{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+		if info.IsDir() || (ext != "json" && unmarshalFuncs[ext] == nil) {
+			return nil
+		}
+
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file, err := i18n.ParseMessageFileBytes(buf, path, unmarshalFuncs)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		c, ok := byTag[file.Tag]
+		if !ok {
+			c = &catalog{Tag: file.Tag, Messages: map[string]*i18n.Message{}}
+			byTag[file.Tag] = c
+		}
+		c.Files = append(c.Files, path)
+		for _, message := range file.Messages {
+			c.Messages[message.ID] = message
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	catalogs := make([]*catalog, 0, len(byTag))
+	for _, c := range byTag {
+		catalogs = append(catalogs, c)
+	}
+	sort.Slice(catalogs, func(i, j int) bool { return catalogs[i].Tag.String() < catalogs[j].Tag.String() })
+	return catalogs, nil
+}
+
+// report is the diff of keys used by source and messages of a catalog
+type report struct {
+	Catalog *catalog
+
+	// Missing keys are required by source, but not in the catalog
+	Missing []*key
+
+	// Unused messages are in the catalog, but not used by source
+	Unused []string
+}
+
+// diff returns missing and unused keys of catalog
+func diff(ks keys, c *catalog) report {
+	r := report{Catalog: c}
+	for _, k := range ks.sorted() {
+		if k.Optional {
+			continue
+		}
+		if _, ok := c.Messages[k.ID]; ok {
+			continue
+		}
+		if _, ok := c.Messages[k.Fallback]; ok && k.Fallback != "" {
+			continue
+		}
+		r.Missing = append(r.Missing, k)
+	}
+
+	for id := range c.Messages {
+		if _, ok := ks[id]; !ok {
+			r.Unused = append(r.Unused, id)
+		}
+	}
+	sort.Strings(r.Unused)
+	return r
+}
+
+// stub returns message file of missing keys in format, whose values are messages of defaults or the IDs
+func stub(missing []*key, defaults *catalog, format string) ([]byte, error) {
+	entries := make(map[string]string, len(missing))
+	for _, k := range missing {
+		entries[k.ID] = k.ID
+		if defaults == nil {
+			continue
+		}
+		if message, ok := defaults.Messages[k.ID]; ok && message.Other != "" {
+			entries[k.ID] = message.Other
+		}
+	}
+
+	switch format {
+	case "toml":
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(entries); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "yaml", "yml":
+		return yaml.Marshal(entries)
+	case "json":
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
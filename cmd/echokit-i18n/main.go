@@ -0,0 +1,173 @@
+// Command echokit-i18n lints message files of languages against message IDs used by Go source,
+// and generates stub entries of missing messages.
+//
+// Usage:
+//
+//	echokit-i18n lint [-src .] [-locales locales] [-tag-name json] [-strict]
+//	echokit-i18n stub [-src .] [-locales locales] [-tag-name json] [-default-lang en] [-format toml] [-out translate]
+//
+// Message IDs are string literals and constants of GetMessage, GetPluralMessage, Lookup of messages.Message,
+// WithMessageID, errors.Register, i18n.LocalizeConfig and i18n.Message, and error codes. Validate tags of structs derive
+// "Field.tag" keys of GetFieldTagMessage, which are satisfied by "tag" keys.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const usage = `Usage: echokit-i18n <command> [flags]
+
+Commands:
+  lint    reports missing and unused messages of each language, and exits with 1 for missing messages
+  stub    writes translate.<lang>.<format> files of missing messages of each language out of -locales
+
+Run "echokit-i18n <command> -h" for flags.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// options are flags of commands
+type options struct {
+	src         string
+	locales     string
+	tagName     string
+	strict      bool
+	defaultLang string
+	format      string
+	out         string
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	command := args[0]
+	flags := flag.NewFlagSet("echokit-i18n "+command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var opts options
+	flags.StringVar(&opts.src, "src", ".", "directory of Go source")
+	flags.StringVar(&opts.locales, "locales", "locales", "directory of message files like active.en.toml")
+	flags.StringVar(&opts.tagName, "tag-name", "json", "struct tag of field names in messages (empty for Go field names)")
+
+	switch command {
+	case "lint":
+		flags.BoolVar(&opts.strict, "strict", false, "exit with 1 for unused messages too")
+	case "stub":
+		flags.StringVar(&opts.defaultLang, "default-lang", "en", "language whose messages are values of stubs")
+		flags.StringVar(&opts.format, "format", "toml", "format of stubs: toml, yaml or json")
+		flags.StringVar(&opts.out, "out", "translate", "directory of stubs, which must be out of -locales as messages.LoadDir loads every message file there")
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", command, usage)
+		return 2
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	s := newScanner(opts.tagName)
+	if err := s.scanDir(opts.src); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	catalogs, err := loadCatalogs(opts.locales)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if len(catalogs) == 0 {
+		fmt.Fprintf(stderr, "no message files in %s\n", opts.locales)
+		return 1
+	}
+
+	if command == "stub" {
+		return runStub(s.keys, catalogs, opts, stdout, stderr)
+	}
+	return runLint(s.keys, catalogs, opts, stdout)
+}
+
+func runLint(ks keys, catalogs []*catalog, opts options, stdout io.Writer) int {
+	code := 0
+	for _, c := range catalogs {
+		r := diff(ks, c)
+		fmt.Fprintf(stdout, "%s %v: %d missing, %d unused\n", c.Tag, c.Files, len(r.Missing), len(r.Unused))
+		for _, k := range r.Missing {
+			fmt.Fprintf(stdout, "  missing %s (%s)\n", k.ID, k.Pos)
+		}
+		for _, id := range r.Unused {
+			fmt.Fprintf(stdout, "  unused %s\n", id)
+		}
+		if len(r.Missing) > 0 || (opts.strict && len(r.Unused) > 0) {
+			code = 1
+		}
+	}
+	return code
+}
+
+func runStub(ks keys, catalogs []*catalog, opts options, stdout, stderr io.Writer) int {
+	switch opts.format {
+	case "toml", "yaml", "yml", "json":
+	default:
+		fmt.Fprintf(stderr, "unknown format %q\n", opts.format)
+		return 2
+	}
+	tag, err := language.Parse(opts.defaultLang)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	var defaults *catalog
+	for _, c := range catalogs {
+		if c.Tag == tag {
+			defaults = c
+		}
+	}
+	out := opts.out
+	if inDir(out, opts.locales) {
+		fmt.Fprintf(stderr, "-out %s must be out of -locales %s, or messages.LoadDir loads untranslated stubs\n", out, opts.locales)
+		return 2
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	for _, c := range catalogs {
+		r := diff(ks, c)
+		if len(r.Missing) == 0 {
+			continue
+		}
+		buf, err := stub(r.Missing, defaults, opts.format)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		path := filepath.Join(out, fmt.Sprintf("translate.%s.%s", c.Tag, opts.format))
+		if err := ioutil.WriteFile(path, buf, 0644); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "%s: %d missing\n", path, len(r.Missing))
+	}
+	return 0
+}
+
+// inDir reports whether path is dir or in dir
+func inDir(path, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTags(t *testing.T) {
+	tests := []struct {
+		haveValidate string
+		wantTags     []string
+	}{
+		{"required", []string{"required"}},
+		{"omitempty,min=3,max=10", []string{"min", "max"}},
+		{"required,email|eq=0", []string{"required", "email", "eq"}},
+		{"dive,keys,alpha,endkeys,required", []string{"alpha", "required"}},
+		{"-", nil},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantTags, validateTags(tt.haveValidate), tt.haveValidate)
+	}
+}
+
+func TestScan(t *testing.T) {
+	s := newScanner("json")
+	assert.NoError(t, s.scanDir("testdata/src"))
+
+	tests := []struct {
+		haveID       string
+		wantOptional bool
+		wantFallback string
+	}{
+		{"users.welcome", false, ""},
+		{"users.count", false, ""},
+		{"users.config", false, ""},
+		{"users.not_found", false, ""},
+		{"users.optional", true, ""},
+		{"users.param", true, ""},
+		{"quota.exceeded", false, ""},
+		{"Gone_Error", true, ""},
+		{"NotExistInDB_Error", true, ""},
+		{"email.required", false, "required"},
+		{"email.email", false, "email"},
+		{"name.min", false, "min"},
+		{"name.eq", false, "eq"},
+		{"Age.gte", false, "gte"},
+		{"tags.required", false, "required"},
+		{"required", true, ""},
+	}
+
+	for _, tt := range tests {
+		k, ok := s.keys[tt.haveID]
+		if assert.True(t, ok, tt.haveID) {
+			assert.Equal(t, tt.wantOptional, k.Optional, tt.haveID)
+			assert.Equal(t, tt.wantFallback, k.Fallback, tt.haveID)
+		}
+	}
+	for _, id := range []string{
+		"test.only", "vendor.only", "Password.required", "name.omitempty", "tags.dive",
+		"cache.key", "plugin.id", "Plugin_Error", "shadow.id", "Shadow_Error",
+	} {
+		assert.NotContains(t, s.keys, id)
+	}
+
+	s = newScanner("")
+	assert.NoError(t, s.scanDir("testdata/src"))
+	assert.Contains(t, s.keys, "Email.required")
+	assert.Contains(t, s.keys, "Password.required")
+}
+
+func TestLint(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"lint", "-src", "testdata/src", "-locales", "testdata/locales"}, &stdout, &stderr)
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, `en [testdata/locales/active.en.toml]: 0 missing, 1 unused
+  unused old
+ja [testdata/locales/active.ja.yaml]: 7 missing, 0 unused
+  missing Age.gte (testdata/src/users/users.go:19:2)
+  missing name.eq (testdata/src/users/users.go:18:2)
+  missing name.min (testdata/src/users/users.go:18:2)
+  missing quota.exceeded (testdata/src/users/users.go:12:20)
+  missing users.config (testdata/src/users/users.go:30:27)
+  missing users.count (testdata/src/users/users.go:29:2)
+  missing users.not_found (testdata/src/users/users.go:35:9)
+`, stdout.String())
+	assert.Empty(t, stderr.String())
+
+	assert.Equal(t, 2, run(nil, &stdout, &stderr))
+	assert.Equal(t, 2, run([]string{"unknown"}, &stdout, &stderr))
+	assert.Equal(t, 1, run([]string{"lint", "-src", "testdata/src", "-locales", "testdata/src"}, &stdout, &stderr))
+}
+
+func TestStub(t *testing.T) {
+	for _, format := range []string{"toml", "yaml", "json"} {
+		dir, err := ioutil.TempDir("", "echokit-i18n")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"stub", "-src", "testdata/src", "-locales", "testdata/locales", "-out", dir, "-format", format}, &stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+
+		path := filepath.Join(dir, "translate.ja."+format)
+		assert.Equal(t, path+": 7 missing\n", stdout.String())
+
+		catalogs, err := loadCatalogs(dir)
+		assert.NoError(t, err)
+		if assert.Len(t, catalogs, 1) {
+			messages := catalogs[0].Messages
+			assert.Len(t, messages, 7, format)
+			assert.Equal(t, "User is not found", messages["users.not_found"].Other, format)
+			assert.Equal(t, "{{.PluralCount}} users", messages["users.count"].Other, format)
+			assert.Equal(t, "name.min", messages["name.min"].Other, format)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 2, run([]string{"stub", "-src", "testdata/src", "-locales", "testdata/locales", "-format", "xml"}, &stdout, &stderr))
+
+	// stubs in -locales would be loaded by messages.LoadDir
+	for _, out := range []string{"testdata/locales", "testdata/locales/translate", "./testdata/locales/"} {
+		stderr.Reset()
+		assert.Equal(t, 2, run([]string{"stub", "-src", "testdata/src", "-locales", "testdata/locales", "-out", out}, &stdout, &stderr), out)
+		assert.Contains(t, stderr.String(), "must be out of -locales", out)
+	}
+	assert.NoDirExists(t, "testdata/locales/translate")
+}
+
+func TestInDir(t *testing.T) {
+	assert.True(t, inDir("locales", "locales"))
+	assert.True(t, inDir("locales/translate", "./locales"))
+	assert.False(t, inDir("translate", "locales"))
+	assert.False(t, inDir("../locales", "locales"))
+	assert.False(t, inDir("locales_translate", "locales"))
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+)
+
+// key is a message ID used by source
+type key struct {
+	ID string
+
+	// Pos is the first use in source, or empty for built-in error codes
+	Pos token.Position
+
+	// Optional keys fall back to other messages when they are missing, like Lookup and error codes
+	Optional bool
+
+	// Fallback is "tag" of "Field.tag" key, which satisfies the key by GetFieldTagMessage
+	Fallback string
+}
+
+// keys are message IDs used by source
+type keys map[string]*key
+
+// add adds message ID, which is required when any use is required
+func (ks keys) add(id string, pos token.Position, optional bool, fallback string) {
+	if id == "" {
+		return
+	}
+	k, ok := ks[id]
+	if !ok {
+		ks[id] = &key{ID: id, Pos: pos, Optional: optional, Fallback: fallback}
+		return
+	}
+	if k.Optional && !optional {
+		k.Optional = false
+		k.Pos = pos
+	}
+	if fallback == "" {
+		k.Fallback = ""
+	}
+}
+
+// sorted returns keys sorted by ID
+func (ks keys) sorted() []*key {
+	sorted := make([]*key, 0, len(ks))
+	for _, k := range ks {
+		sorted = append(sorted, k)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	return sorted
+}
+
+// nonErrorTags are validate tags which do not fail by themselves
+var nonErrorTags = map[string]bool{
+	"":              true,
+	"-":             true,
+	"omitempty":     true,
+	"dive":          true,
+	"keys":          true,
+	"endkeys":       true,
+	"structonly":    true,
+	"nostructlevel": true,
+}
+
+// scanner collects message IDs of Go files
+type scanner struct {
+	fset *token.FileSet
+	keys keys
+
+	// tagName is struct tag of field names in messages, like RegisterTagNameFunc of validator (empty for Go names)
+	tagName string
+}
+
+func newScanner(tagName string) *scanner {
+	s := &scanner{fset: token.NewFileSet(), keys: keys{}, tagName: tagName}
+	// httperrors.HTTPErrorHandler localizes errors by message ID of codes, or the code
+	for _, info := range errors.Codes() {
+		id := info.MessageID
+		if id == "" {
+			id = string(info.Code)
+		}
+		s.keys.add(id, token.Position{}, true, "")
+	}
+	return s
+}
+
+// scanDir scans Go files of root and its subdirectories, except tests, testdata, vendor and hidden directories
+func (s *scanner) scanDir(root string) error {
+	packages := map[string][]*ast.File{}
+	var dirs []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(s.fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(path)
+		if _, ok := packages[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		packages[dir] = append(packages[dir], file)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		s.scanPackage(packages[dir])
+	}
+	return nil
+}
+
+// scanPackage scans files of a package, whose string constants are resolved
+func (s *scanner) scanPackage(files []*ast.File) {
+	consts := map[string]string{}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for i, name := range valueSpec.Names {
+					if i >= len(valueSpec.Values) {
+						break
+					}
+					if value, ok := stringValue(valueSpec.Values[i], nil); ok {
+						consts[name.Name] = value
+					}
+				}
+			}
+		}
+	}
+
+	for _, file := range files {
+		imports := newFileImports(file)
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.CallExpr:
+				s.scanCall(n, consts, imports)
+			case *ast.CompositeLit:
+				s.scanCompositeLit(n, consts)
+			case *ast.StructType:
+				s.scanStruct(n)
+			}
+			return true
+		})
+	}
+}
+
+// scanCall collects IDs of messages.Message, errors.Error and errors.Register
+func (s *scanner) scanCall(call *ast.CallExpr, consts map[string]string, imports fileImports) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) == 0 {
+		return
+	}
+	pos := s.fset.Position(call.Pos())
+
+	switch sel.Sel.Name {
+	case "GetMessage", "GetPluralMessage", "WithMessageID":
+		if id, ok := stringValue(call.Args[0], consts); ok {
+			s.keys.add(id, pos, false, "")
+		}
+	case "Lookup":
+		if len(call.Args) != 2 || !imports.isMessage(sel.X) {
+			return
+		}
+		if id, ok := stringValue(call.Args[0], consts); ok {
+			s.keys.add(id, pos, true, "")
+		}
+	case "Register", "MustRegister":
+		// Register(code, status, description, messageID)
+		if len(call.Args) != 4 || !imports.isPackage(sel.X, imports.errors) {
+			return
+		}
+		if id, ok := stringValue(call.Args[3], consts); ok && id != "" {
+			s.keys.add(id, pos, false, "")
+		} else if code, ok := stringValue(call.Args[0], consts); ok {
+			s.keys.add(code, pos, true, "")
+		}
+	}
+}
+
+const (
+	messagesPath = "github.com/rakutentech/go-echo-kit/messages"
+	errorsPath   = "github.com/rakutentech/go-echo-kit/errors"
+)
+
+// fileImports are names of kit packages imported by a file, or empty strings
+type fileImports struct {
+	messages string
+	errors   string
+}
+
+func newFileImports(file *ast.File) fileImports {
+	var imports fileImports
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := filepath.Base(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		switch path {
+		case messagesPath:
+			imports.messages = name
+		case errorsPath:
+			imports.errors = name
+		}
+	}
+	return imports
+}
+
+// isPackage reports whether expr is the package imported as name, not a variable shadowing it
+func (imports fileImports) isPackage(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && name != "" && name != "_" && ident.Name == name && ident.Obj == nil
+}
+
+// isMessage reports whether expr is messages.Message, which is
+// a call of messages.NewMessage or a variable declared with it or its type
+func (imports fileImports) isMessage(expr ast.Expr) bool {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return imports.isMessage(x.X)
+	case *ast.StarExpr:
+		return imports.isMessage(x.X)
+	case *ast.CallExpr:
+		sel, ok := x.Fun.(*ast.SelectorExpr)
+		return ok && sel.Sel.Name == "NewMessage" && imports.isPackage(sel.X, imports.messages)
+	case *ast.SelectorExpr:
+		// type messages.Message
+		return x.Sel.Name == "Message" && imports.isPackage(x.X, imports.messages)
+	case *ast.Ident:
+		if x.Obj == nil || x.Obj.Kind != ast.Var {
+			return false
+		}
+		switch decl := x.Obj.Decl.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range decl.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name == x.Name && i < len(decl.Rhs) && len(decl.Lhs) == len(decl.Rhs) {
+					return imports.isMessage(decl.Rhs[i])
+				}
+			}
+		case *ast.ValueSpec:
+			if decl.Type != nil {
+				return imports.isMessage(decl.Type)
+			}
+			for i, name := range decl.Names {
+				if name.Name == x.Name && i < len(decl.Values) {
+					return imports.isMessage(decl.Values[i])
+				}
+			}
+		case *ast.Field:
+			return imports.isMessage(decl.Type)
+		}
+	}
+	return false
+}
+
+// scanCompositeLit collects IDs of i18n.LocalizeConfig and i18n.Message
+func (s *scanner) scanCompositeLit(lit *ast.CompositeLit, consts map[string]string) {
+	sel, ok := lit.Type.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	var field string
+	switch sel.Sel.Name {
+	case "LocalizeConfig":
+		field = "MessageID"
+	case "Message":
+		if x, ok := sel.X.(*ast.Ident); !ok || x.Name != "i18n" {
+			return
+		}
+		field = "ID"
+	default:
+		return
+	}
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if k, ok := kv.Key.(*ast.Ident); !ok || k.Name != field {
+			continue
+		}
+		if id, ok := stringValue(kv.Value, consts); ok {
+			s.keys.add(id, s.fset.Position(kv.Pos()), false, "")
+		}
+	}
+}
+
+// scanStruct collects "Field.tag" keys of validate tags, which fall back to "tag" keys
+func (s *scanner) scanStruct(st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		if field.Tag == nil || len(field.Names) == 0 {
+			continue
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		structTag := reflect.StructTag(tag)
+		validate := structTag.Get("validate")
+		if validate == "" || validate == "-" {
+			continue
+		}
+
+		var names []string
+		name := ""
+		if s.tagName != "" {
+			name = strings.SplitN(structTag.Get(s.tagName), ",", 2)[0]
+		}
+		switch name {
+		case "-":
+			continue
+		case "":
+			for _, n := range field.Names {
+				names = append(names, n.Name)
+			}
+		default:
+			names = []string{name}
+		}
+
+		pos := s.fset.Position(field.Pos())
+		for _, t := range validateTags(validate) {
+			s.keys.add(t, pos, true, "")
+			for _, n := range names {
+				s.keys.add(n+"."+t, pos, false, t)
+			}
+		}
+	}
+}
+
+// validateTags returns tags like "required" and "min" of validate tag like "required,min=3|eq=0"
+func validateTags(validate string) []string {
+	var tags []string
+	for _, part := range strings.Split(validate, ",") {
+		for _, alt := range strings.Split(part, "|") {
+			t := strings.TrimSpace(strings.SplitN(alt, "=", 2)[0])
+			if !nonErrorTags[t] {
+				tags = append(tags, t)
+			}
+		}
+	}
+	return tags
+}
+
+// stringValue returns value of string literal, or constant of the package
+func stringValue(expr ast.Expr, consts map[string]string) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(e.Value)
+		return value, err == nil
+	case *ast.Ident:
+		value, ok := consts[e.Name]
+		return value, ok
+	}
+	return "", false
+}
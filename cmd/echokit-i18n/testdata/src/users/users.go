@@ -0,0 +1,49 @@
+package users
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/rakutentech/go-echo-kit/errors"
+	kitmessages "github.com/rakutentech/go-echo-kit/messages"
+)
+
+const msgWelcome = "users.welcome"
+
+var ErrCodeQuota = errors.MustRegister("Quota_Error", 429, "Quota is exceeded", "quota.exceeded")
+
+var ErrCodeGone = errors.MustRegister("Gone_Error", 410, "Resource is gone", "")
+
+type createRequest struct {
+	Email    string   `json:"email" validate:"required,email"`
+	Name     string   `json:"name,omitempty" validate:"omitempty,min=3|eq=0"`
+	Age      int      `validate:"gte=20"`
+	Password string   `json:"-" validate:"required"`
+	Tags     []string `json:"tags" validate:"dive,required"`
+}
+
+func welcome(c echo.Context) error {
+	m := kitmessages.NewMessage(c)
+	if msg, ok := m.Lookup("users.optional", nil); ok {
+		return c.String(200, msg)
+	}
+	m.GetPluralMessage("users.count", 2, nil)
+	_ = &i18n.LocalizeConfig{MessageID: "users.config"}
+	return c.String(200, m.GetMessage(msgWelcome, nil))
+}
+
+func notFound() error {
+	return errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user not found").WithMessageID("users.not_found")
+}
+
+func lookupParam(m *kitmessages.Message) string {
+	msg, _ := m.Lookup("users.param", nil)
+	return msg
+}
+
+// Lookup and Register of other packages are not messages
+func others(cache map[string]string, registry plugins) {
+	cache.Lookup("cache.key", nil)
+	registry.Register("Plugin_Error", 1, "plugin", "plugin.id")
+	var errors plugins
+	errors.MustRegister("Shadow_Error", 1, "shadow", "shadow.id")
+}
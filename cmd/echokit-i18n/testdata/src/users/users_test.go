@@ -0,0 +1,5 @@
+package users
+
+func testMessage(m messages.Message) string {
+	return m.GetMessage("test.only", nil)
+}
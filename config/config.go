@@ -51,10 +51,7 @@ func new() *viper.Viper {
 
 	// Dotenv and os env merge phase
 	loadEnv()
-
-	for _, key := range viper.AllKeys() {
-		mergeEnv(key, viper)
-	}
+	mergeEnv(viper)
 
 	return viper
 }
@@ -96,11 +93,38 @@ func loadEnv() {
 	}
 }
 
-func mergeEnv(key string, viper *viper.Viper) {
-	value := viper.GetString(key)
-	if needEnvVariable(value) {
-		viper.Set(key, getEnv(extractValue(value)))
+// mergeEnv replaces "${ENV}" values, including those in lists, with environment variables.
+// The settings are merged as a whole, because Set of a nested key shadows its parents in Sub
+func mergeEnv(viper *viper.Viper) {
+	settings := viper.AllSettings()
+	for key, value := range settings {
+		settings[key] = resolveEnv(value)
+	}
+	if err := viper.MergeConfigMap(settings); err != nil {
+		panic(err)
+	}
+}
+
+func resolveEnv(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if needEnvVariable(v) {
+			return getEnv(extractValue(v))
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = resolveEnv(item)
+		}
+	case map[interface{}]interface{}:
+		for key, item := range v {
+			v[key] = resolveEnv(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = resolveEnv(item)
+		}
 	}
+	return value
 }
 
 func needEnvVariable(value string) bool {
@@ -2,9 +2,10 @@ package context
 
 import (
 	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/rakutentech/go-echo-kit/filter"
+	"github.com/rakutentech/go-echo-kit/pagination"
 )
 
 // CustomContext ...
@@ -17,24 +18,31 @@ func NewCustomContext(c echo.Context) CustomContext {
 	return CustomContext{c}
 }
 
-// ParamUint64 ...
+// ParamUint64 returns 0 for invalid and negative numbers. Use ParamUint64E to know the error
 func (cc *CustomContext) ParamUint64(key string) uint64 {
-	value, _ := strconv.Atoi(cc.Param(key))
-	return uint64(value)
+	value, _ := strconv.ParseUint(cc.Param(key), 10, 64)
+	return value
 }
 
-// QueryUint64 ...
+// QueryUint64 returns 0 for invalid and negative numbers. Use QueryUint64E to know the error
 func (cc *CustomContext) QueryUint64(key string) uint64 {
-	value, _ := strconv.Atoi(cc.QueryParam(key))
-	return uint64(value)
+	value, _ := strconv.ParseUint(cc.QueryParam(key), 10, 64)
+	return value
 }
 
-// ParamTime ...
-func (cc *CustomContext) ParamTime(key string) (time.Time, error) {
-	date := cc.Param(key)
-	return stringToTime(date)
+// Pagination parses page, per_page, sort and cursor query parameters
+func (cc *CustomContext) Pagination(config pagination.Config) (pagination.Pagination, error) {
+	return pagination.Parse(cc, config)
 }
 
-func stringToTime(date string) (time.Time, error) {
-	return time.Parse(time.RFC3339, date)
+// Filter parses filter query parameters like created_at[gte]= against allow-listed fields.
+// Values of filter.TypeTime are parsed by ParseTime, and values of lte by ParseTimeEnd unless config has TimeParser
+func (cc *CustomContext) Filter(config filter.Config) (filter.Filter, error) {
+	if config.TimeParser == nil {
+		config.TimeParser = ParseTime
+		if config.EndTimeParser == nil {
+			config.EndTimeParser = ParseTimeEnd
+		}
+	}
+	return filter.Parse(cc, config)
 }
@@ -0,0 +1,36 @@
+package contexttest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rakutentech/go-echo-kit/context"
+)
+
+// NewContext builds CustomContext of request with path params for handler tests
+// Usage cc, rec := contexttest.NewContext(httptest.NewRequest(http.MethodGet, "/users/1", nil), map[string]string{"id": "1"})
+func NewContext(req *http.Request, params map[string]string) (*context.CustomContext, *httptest.ResponseRecorder) {
+	return NewContextWithEcho(echo.New(), req, params)
+}
+
+// NewContextWithEcho is NewContext with echo instance which has Validator, Binder and so on
+func NewContextWithEcho(e *echo.Echo, req *http.Request, params map[string]string) (*context.CustomContext, *httptest.ResponseRecorder) {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make([]string, 0, len(params))
+	for _, name := range names {
+		values = append(values, params[name])
+	}
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	// SetParamNames allocates values by the number of names, without routes of e
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+	return context.From(c), rec
+}
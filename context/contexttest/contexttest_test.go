@@ -0,0 +1,69 @@
+package contexttest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rakutentech/go-echo-kit/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func getUser(cc *context.CustomContext) error {
+	id, err := cc.ParamUint64E("id")
+	if err != nil {
+		return err
+	}
+	return cc.JSON(http.StatusOK, map[string]interface{}{"id": id, "group": cc.Param("group")})
+}
+
+func TestNewContext(t *testing.T) {
+	cc, rec := NewContext(httptest.NewRequest(http.MethodGet, "/groups/a/users/1", nil), map[string]string{"group": "a", "id": "1"})
+
+	assert.NoError(t, getUser(cc))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":1,"group":"a"}`, rec.Body.String())
+
+	cc, _ = NewContext(httptest.NewRequest(http.MethodGet, "/groups/a/users/x", nil), map[string]string{"id": "x"})
+	assert.Error(t, getUser(cc))
+}
+
+func TestNewContextWithEcho(t *testing.T) {
+	e := echo.New()
+	cc, _ := NewContextWithEcho(e, httptest.NewRequest(http.MethodGet, "/a/b/c", nil), map[string]string{"a": "1", "b": "2", "c": "3"})
+
+	assert.Equal(t, "1", cc.Param("a"))
+	assert.Equal(t, "3", cc.Param("c"))
+	assert.Empty(t, e.Routes())
+}
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(context.Middleware())
+	e.GET("/users/:id", context.Handler(getUser))
+	e.GET("/plain/:id", func(c echo.Context) error {
+		_, ok := c.(*context.CustomContext)
+		assert.True(t, ok)
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":42,"group":""}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/plain/1", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestHandlerWithoutMiddleware(t *testing.T) {
+	e := echo.New()
+	e.GET("/users/:id", context.Handler(getUser))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":7,"group":""}`, rec.Body.String())
+}
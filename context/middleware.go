@@ -0,0 +1,42 @@
+package context
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rakutentech/go-echo-kit/logger"
+)
+
+// Middleware wraps echo.Context of every request in CustomContext
+// Usage e.Use(context.Middleware())
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := c.(*CustomContext); ok {
+				return next(c)
+			}
+			return next(&CustomContext{c})
+		}
+	}
+}
+
+// From returns CustomContext installed by Middleware, or wraps c
+func From(c echo.Context) *CustomContext {
+	if cc, ok := c.(*CustomContext); ok {
+		return cc
+	}
+	return &CustomContext{c}
+}
+
+// Handler adapts handler of CustomContext to echo.HandlerFunc
+// Usage e.GET("/users/:id", context.Handler(getUser))
+func Handler(h func(cc *CustomContext) error) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return h(From(c))
+	}
+}
+
+// RequestIDHandler stores request ID into request context for loggers like SQL logger (see logger.RequestID)
+// Usage middleware.RequestIDWithConfig(middleware.RequestIDConfig{RequestIDHandler: context.RequestIDHandler})
+func RequestIDHandler(c echo.Context, requestID string) {
+	req := c.Request()
+	c.SetRequest(req.WithContext(logger.WithRequestID(req.Context(), requestID)))
+}
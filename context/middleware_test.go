@@ -0,0 +1,28 @@
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/rakutentech/go-echo-kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDHandler(t *testing.T) {
+	e := echo.New()
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		Generator:        func() string { return "rid" },
+		RequestIDHandler: RequestIDHandler,
+	}))
+
+	var haveRequestID string
+	e.GET("/", func(c echo.Context) error {
+		haveRequestID = logger.RequestID(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	})
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "rid", haveRequestID)
+}
@@ -0,0 +1,232 @@
+package context
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func newParamError(key, value, expected string) errors.Error {
+	return errors.NewErrorWithMsgf(errors.ErrCodeParameterIllegalState, "%s must be %s: %q", key, expected, value)
+}
+
+func newMissingParamError(key string) errors.Error {
+	return errors.NewErrorWithMsgf(errors.ErrCodeMissingParamsError, "%s is required", key)
+}
+
+func parseInt(key, value string, bitSize int) (int64, error) {
+	if value == "" {
+		return 0, newMissingParamError(key)
+	}
+	parsed, err := strconv.ParseInt(value, 10, bitSize)
+	if err != nil {
+		return 0, newParamError(key, value, "integer")
+	}
+	return parsed, nil
+}
+
+func parseUint64(key, value string) (uint64, error) {
+	if value == "" {
+		return 0, newMissingParamError(key)
+	}
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, newParamError(key, value, "unsigned integer")
+	}
+	return parsed, nil
+}
+
+// ParamInt returns path parameter as int,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) ParamInt(key string) (int, error) {
+	value, err := parseInt(key, cc.Param(key), strconv.IntSize)
+	return int(value), err
+}
+
+// ParamInt64 returns path parameter as int64,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) ParamInt64(key string) (int64, error) {
+	return parseInt(key, cc.Param(key), 64)
+}
+
+// ParamUint64E returns path parameter as uint64, or errors.Error with ErrCodeMissingParamsError
+// when it is empty and ErrCodeParameterIllegalState for invalid and negative numbers
+func (cc *CustomContext) ParamUint64E(key string) (uint64, error) {
+	return parseUint64(key, cc.Param(key))
+}
+
+// ParamUUID returns path parameter as lower case UUID,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) ParamUUID(key string) (string, error) {
+	value := cc.Param(key)
+	if value == "" {
+		return "", newMissingParamError(key)
+	}
+	if !uuidPattern.MatchString(value) {
+		return "", newParamError(key, value, "UUID")
+	}
+	return strings.ToLower(value), nil
+}
+
+// MustParamInt is ParamInt which panics with the error. Use it with middleware.Recover
+func (cc *CustomContext) MustParamInt(key string) int {
+	value, err := cc.ParamInt(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// MustParamInt64 is ParamInt64 which panics with the error
+func (cc *CustomContext) MustParamInt64(key string) int64 {
+	value, err := cc.ParamInt64(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// MustParamUint64 is ParamUint64E which panics with the error
+func (cc *CustomContext) MustParamUint64(key string) uint64 {
+	value, err := cc.ParamUint64E(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// MustParamUUID is ParamUUID which panics with the error
+func (cc *CustomContext) MustParamUUID(key string) string {
+	value, err := cc.ParamUUID(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// QueryInt returns query parameter as int,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) QueryInt(key string) (int, error) {
+	value, err := parseInt(key, cc.QueryParam(key), strconv.IntSize)
+	return int(value), err
+}
+
+// QueryInt64 returns query parameter as int64,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) QueryInt64(key string) (int64, error) {
+	return parseInt(key, cc.QueryParam(key), 64)
+}
+
+// QueryUint64E returns query parameter as uint64,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) QueryUint64E(key string) (uint64, error) {
+	return parseUint64(key, cc.QueryParam(key))
+}
+
+// QueryBool returns query parameter as bool (1, t, true, 0, f, false ...),
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) QueryBool(key string) (bool, error) {
+	value := cc.QueryParam(key)
+	if value == "" {
+		return false, newMissingParamError(key)
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, newParamError(key, value, "boolean")
+	}
+	return parsed, nil
+}
+
+// QueryFloat returns query parameter as finite float64,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) QueryFloat(key string) (float64, error) {
+	value := cc.QueryParam(key)
+	if value == "" {
+		return 0, newMissingParamError(key)
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+		return 0, newParamError(key, value, "number")
+	}
+	return parsed, nil
+}
+
+// QueryDuration returns query parameter like 1h30m as time.Duration,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) QueryDuration(key string) (time.Duration, error) {
+	value := cc.QueryParam(key)
+	if value == "" {
+		return 0, newMissingParamError(key)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, newParamError(key, value, "duration")
+	}
+	return parsed, nil
+}
+
+// QueryStrings returns non-empty values of repeated query parameter like ?tag=a&tag=b
+func (cc *CustomContext) QueryStrings(key string) []string {
+	var values []string
+	for _, value := range cc.QueryParams()[key] {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// QueryIntDefault returns query parameter as int, or defaultValue when it is not given.
+// Invalid values still return the error
+func (cc *CustomContext) QueryIntDefault(key string, defaultValue int) (int, error) {
+	if cc.QueryParam(key) == "" {
+		return defaultValue, nil
+	}
+	return cc.QueryInt(key)
+}
+
+// QueryInt64Default returns query parameter as int64, or defaultValue when it is not given
+func (cc *CustomContext) QueryInt64Default(key string, defaultValue int64) (int64, error) {
+	if cc.QueryParam(key) == "" {
+		return defaultValue, nil
+	}
+	return cc.QueryInt64(key)
+}
+
+// QueryUint64Default returns query parameter as uint64, or defaultValue when it is not given
+func (cc *CustomContext) QueryUint64Default(key string, defaultValue uint64) (uint64, error) {
+	if cc.QueryParam(key) == "" {
+		return defaultValue, nil
+	}
+	return cc.QueryUint64E(key)
+}
+
+// QueryBoolDefault returns query parameter as bool, or defaultValue when it is not given
+func (cc *CustomContext) QueryBoolDefault(key string, defaultValue bool) (bool, error) {
+	if cc.QueryParam(key) == "" {
+		return defaultValue, nil
+	}
+	return cc.QueryBool(key)
+}
+
+// QueryFloatDefault returns query parameter as float64, or defaultValue when it is not given
+func (cc *CustomContext) QueryFloatDefault(key string, defaultValue float64) (float64, error) {
+	if cc.QueryParam(key) == "" {
+		return defaultValue, nil
+	}
+	return cc.QueryFloat(key)
+}
+
+// QueryDurationDefault returns query parameter as time.Duration, or defaultValue when it is not given
+func (cc *CustomContext) QueryDurationDefault(key string, defaultValue time.Duration) (time.Duration, error) {
+	if cc.QueryParam(key) == "" {
+		return defaultValue, nil
+	}
+	return cc.QueryDuration(key)
+}
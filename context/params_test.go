@@ -0,0 +1,140 @@
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rakutentech/go-echo-kit/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestContext(target string, params map[string]string) CustomContext {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	e := echo.New()
+	// echo allocates path parameters by its routes
+	e.GET("/:a/:b/:c", echo.NotFoundHandler)
+	c := e.NewContext(req, httptest.NewRecorder())
+	var names, values []string
+	for name, value := range params {
+		names = append(names, name)
+		values = append(values, value)
+	}
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+	return NewCustomContext(c)
+}
+
+func assertErrorCode(t *testing.T, want errors.ErrorCode, err error, msg string) {
+	if assert.Error(t, err, msg) {
+		assert.Equal(t, string(want), err.(errors.Error).ErrorCode(), msg)
+	}
+}
+
+func TestParamUint64(t *testing.T) {
+	tests := []struct {
+		haveID   string
+		want     uint64
+		wantCode errors.ErrorCode
+	}{
+		{"42", 42, ""},
+		{"18446744073709551615", 18446744073709551615, ""},
+		{"-1", 0, errors.ErrCodeParameterIllegalState},
+		{"abc", 0, errors.ErrCodeParameterIllegalState},
+		{"", 0, errors.ErrCodeMissingParamsError},
+	}
+
+	for _, tt := range tests {
+		cc := newTestContext("/users/"+tt.haveID, map[string]string{"id": tt.haveID})
+		assert.Equal(t, tt.want, cc.ParamUint64("id"), tt.haveID)
+
+		got, err := cc.ParamUint64E("id")
+		assert.Equal(t, tt.want, got, tt.haveID)
+		if tt.wantCode == "" {
+			assert.NoError(t, err, tt.haveID)
+			assert.Equal(t, tt.want, cc.MustParamUint64("id"))
+		} else {
+			assertErrorCode(t, tt.wantCode, err, tt.haveID)
+			assert.Panics(t, func() { cc.MustParamUint64("id") })
+		}
+	}
+}
+
+func TestParamInt(t *testing.T) {
+	cc := newTestContext("/", map[string]string{"offset": "-5", "id": "9223372036854775808"})
+
+	offset, err := cc.ParamInt("offset")
+	assert.NoError(t, err)
+	assert.Equal(t, -5, offset)
+
+	_, err = cc.ParamInt64("id")
+	assertErrorCode(t, errors.ErrCodeParameterIllegalState, err, "overflow")
+}
+
+func TestParamUUID(t *testing.T) {
+	cc := newTestContext("/", map[string]string{"id": "123E4567-E89B-12D3-A456-426614174000", "bad": "123e4567"})
+
+	id, err := cc.ParamUUID("id")
+	assert.NoError(t, err)
+	assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", id)
+
+	_, err = cc.ParamUUID("bad")
+	assertErrorCode(t, errors.ErrCodeParameterIllegalState, err, "bad")
+}
+
+func TestQuery(t *testing.T) {
+	cc := newTestContext("/?active=true&ratio=0.5&timeout=1m30s&tag=a&tag=&tag=b&limit=-1&bad=x&nan=NaN&inf=Inf&ninf=-Inf&huge=1e400", nil)
+
+	active, err := cc.QueryBool("active")
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	ratio, err := cc.QueryFloat("ratio")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, ratio)
+
+	timeout, err := cc.QueryDuration("timeout")
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Second, timeout)
+
+	assert.Equal(t, []string{"a", "b"}, cc.QueryStrings("tag"))
+	assert.Nil(t, cc.QueryStrings("missing"))
+
+	assert.Equal(t, uint64(0), cc.QueryUint64("limit"))
+	_, err = cc.QueryUint64E("limit")
+	assertErrorCode(t, errors.ErrCodeParameterIllegalState, err, "limit")
+
+	_, err = cc.QueryBool("bad")
+	assertErrorCode(t, errors.ErrCodeParameterIllegalState, err, "bool")
+	_, err = cc.QueryFloat("bad")
+	assertErrorCode(t, errors.ErrCodeParameterIllegalState, err, "float")
+	for _, key := range []string{"nan", "inf", "ninf", "huge"} {
+		_, err = cc.QueryFloat(key)
+		assertErrorCode(t, errors.ErrCodeParameterIllegalState, err, key)
+	}
+	_, err = cc.QueryDuration("bad")
+	assertErrorCode(t, errors.ErrCodeParameterIllegalState, err, "duration")
+	_, err = cc.QueryInt("missing")
+	assertErrorCode(t, errors.ErrCodeMissingParamsError, err, "missing")
+}
+
+func TestQueryDefault(t *testing.T) {
+	cc := newTestContext("/?per_page=50&bad=x", nil)
+
+	perPage, err := cc.QueryIntDefault("per_page", 20)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, perPage)
+
+	page, err := cc.QueryIntDefault("page", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page)
+
+	timeout, err := cc.QueryDurationDefault("timeout", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, timeout)
+
+	_, err = cc.QueryBoolDefault("bad", true)
+	assertErrorCode(t, errors.ErrCodeParameterIllegalState, err, "bad")
+}
@@ -0,0 +1,177 @@
+package context
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+)
+
+// TimeConfig defines how ParamTime, QueryTime and FormTime parse values
+type TimeConfig struct {
+	// Layouts are tried in order. Digits only values which match no layout are parsed as unix seconds or millis
+	Layouts []string
+
+	// Location is used for layouts without zone like 2006-01-02 (default: UTC)
+	Location *time.Location
+}
+
+// DefaultTimeConfig ...
+var DefaultTimeConfig = TimeConfig{
+	Layouts: []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+		"20060102",
+	},
+	Location: time.UTC,
+}
+
+var timeConfig atomic.Value
+
+// SetTimeConfig sets layouts and default location of time parameters
+// Usage loc, _ := time.LoadLocation("Asia/Tokyo"); context.SetTimeConfig(context.TimeConfig{Location: loc})
+func SetTimeConfig(config TimeConfig) {
+	if len(config.Layouts) == 0 {
+		config.Layouts = DefaultTimeConfig.Layouts
+	}
+	if config.Location == nil {
+		config.Location = DefaultTimeConfig.Location
+	}
+	timeConfig.Store(config)
+}
+
+func currentTimeConfig() TimeConfig {
+	if config, ok := timeConfig.Load().(TimeConfig); ok {
+		return config
+	}
+	return DefaultTimeConfig
+}
+
+// unix millis are larger than this, which is year 5138 in unix seconds
+const unixMillisThreshold = 100000000000
+
+// ParseTime parses value with layouts of TimeConfig, or as unix seconds or millis
+func ParseTime(value string) (time.Time, error) {
+	parsed, _, err := parseTime(value)
+	return parsed, err
+}
+
+// ParseTimeEnd is ParseTime which returns the end of the day for date only values like 2006-01-02,
+// so that inclusive ends of ranges include the day
+func ParseTimeEnd(value string) (time.Time, error) {
+	parsed, dateOnly, err := parseTime(value)
+	if err == nil && dateOnly {
+		parsed = endOfDay(parsed)
+	}
+	return parsed, err
+}
+
+func endOfDay(t time.Time) time.Time {
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// parseTime also returns whether the matched layout is date only like 2006-01-02
+func parseTime(value string) (time.Time, bool, error) {
+	config := currentTimeConfig()
+
+	var err error
+	for _, layout := range config.Layouts {
+		var parsed time.Time
+		// ParseInLocation uses zone of value when it has
+		if parsed, err = time.ParseInLocation(layout, value, config.Location); err == nil {
+			return parsed, isDateLayout(layout), nil
+		}
+	}
+
+	// digits like 20240501 are dates when a layout matches
+	if unix, unixErr := strconv.ParseInt(value, 10, 64); unixErr == nil {
+		if unix >= unixMillisThreshold || unix <= -unixMillisThreshold {
+			return time.Unix(0, unix*int64(time.Millisecond)).In(config.Location), false, nil
+		}
+		return time.Unix(unix, 0).In(config.Location), false, nil
+	}
+	return time.Time{}, false, err
+}
+
+// isDateLayout reports whether layout has no clock like 2006-01-02
+func isDateLayout(layout string) bool {
+	for _, clock := range []string{"15", "03", "3:04", time.Kitchen} {
+		if strings.Contains(layout, clock) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseTimeParam(key, value string) (time.Time, error) {
+	parsed, _, err := parseTimeParamLayout(key, value)
+	return parsed, err
+}
+
+func parseTimeParamLayout(key, value string) (time.Time, bool, error) {
+	if value == "" {
+		return time.Time{}, false, newMissingParamError(key)
+	}
+	parsed, dateOnly, err := parseTime(value)
+	if err != nil {
+		return time.Time{}, false, newParamError(key, value, "time")
+	}
+	return parsed, dateOnly, nil
+}
+
+// ParamTime returns path parameter as time,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) ParamTime(key string) (time.Time, error) {
+	return parseTimeParam(key, cc.Param(key))
+}
+
+// QueryTime returns query parameter as time,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) QueryTime(key string) (time.Time, error) {
+	return parseTimeParam(key, cc.QueryParam(key))
+}
+
+// FormTime returns form value as time,
+// or errors.Error with ErrCodeMissingParamsError when it is empty and ErrCodeParameterIllegalState when it is invalid
+func (cc *CustomContext) FormTime(key string) (time.Time, error) {
+	return parseTimeParam(key, cc.FormValue(key))
+}
+
+// TimeRange is from and to of query. Zero value means it is not given
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// QueryTimeRange returns optional from and to query parameters like ?from=2024-05-01&to=2024-05-31.
+// Date only to is the end of the day, so that the range includes it.
+// It returns errors.Error with ErrCodeValidationError when from is after to
+func (cc *CustomContext) QueryTimeRange(fromKey, toKey string) (TimeRange, error) {
+	timeRange := TimeRange{}
+	if cc.QueryParam(fromKey) != "" {
+		from, err := cc.QueryTime(fromKey)
+		if err != nil {
+			return timeRange, err
+		}
+		timeRange.From = from
+	}
+	if cc.QueryParam(toKey) != "" {
+		to, dateOnly, err := parseTimeParamLayout(toKey, cc.QueryParam(toKey))
+		if err != nil {
+			return timeRange, err
+		}
+		if dateOnly {
+			to = endOfDay(to)
+		}
+		timeRange.To = to
+	}
+
+	if !timeRange.From.IsZero() && !timeRange.To.IsZero() && timeRange.From.After(timeRange.To) {
+		return timeRange, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s must not be after %s", fromKey, toKey)
+	}
+	return timeRange, nil
+}
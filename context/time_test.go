@@ -0,0 +1,120 @@
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rakutentech/go-echo-kit/errors"
+	"github.com/rakutentech/go-echo-kit/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTime(t *testing.T) {
+	tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+	SetTimeConfig(TimeConfig{Location: tokyo})
+	defer SetTimeConfig(DefaultTimeConfig)
+
+	tests := []struct {
+		haveValue string
+		want      time.Time
+		wantErr   bool
+	}{
+		{"2024-05-01T10:00:00Z", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), false},
+		{"2024-05-01T10:00:00.123+09:00", time.Date(2024, 5, 1, 1, 0, 0, 123000000, time.UTC), false},
+		{"2024-05-01T10:00:00", time.Date(2024, 5, 1, 10, 0, 0, 0, tokyo), false},
+		{"2024-05-01 10:00:00", time.Date(2024, 5, 1, 10, 0, 0, 0, tokyo), false},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, tokyo), false},
+		{"1714557600", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), false},
+		{"1714557600123", time.Date(2024, 5, 1, 10, 0, 0, 123000000, time.UTC), false},
+		{"20240501", time.Date(2024, 5, 1, 0, 0, 0, 0, tokyo), false},
+		{"20241301", time.Unix(20241301, 0), false}, // month 13 is not a date
+		{"2024/05/01", time.Time{}, true},
+		{"tomorrow", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTime(tt.haveValue)
+		if tt.wantErr {
+			assert.Error(t, err, tt.haveValue)
+			continue
+		}
+		assert.NoError(t, err, tt.haveValue)
+		assert.True(t, tt.want.Equal(got), "%s: want %v but got %v", tt.haveValue, tt.want, got)
+	}
+}
+
+func TestTimeAccessors(t *testing.T) {
+	cc := newTestContext("/?since=2024-05-01&bad=x", map[string]string{"date": "2024-05-02"})
+
+	date, err := cc.ParamTime("date")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), date)
+
+	since, err := cc.QueryTime("since")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), since)
+
+	_, err = cc.QueryTime("bad")
+	assertErrorCode(t, errors.ErrCodeParameterIllegalState, err, "bad")
+	_, err = cc.QueryTime("missing")
+	assertErrorCode(t, errors.ErrCodeMissingParamsError, err, "missing")
+
+	form := url.Values{"at": {"1714557600"}}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	formContext := NewCustomContext(echo.New().NewContext(req, httptest.NewRecorder()))
+	at, err := formContext.FormTime("at")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1714557600), at.Unix())
+}
+
+func TestQueryTimeRange(t *testing.T) {
+	endOfDay := time.Date(2024, 5, 31, 23, 59, 59, 999999999, time.UTC)
+	tests := []struct {
+		haveQuery string
+		wantFrom  time.Time
+		wantTo    time.Time
+		wantCode  errors.ErrorCode
+	}{
+		{"", time.Time{}, time.Time{}, ""},
+		{"?from=2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Time{}, ""},
+		{"?from=2024-05-01&to=2024-05-31", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), endOfDay, ""},
+		{"?from=2024-05-01&to=20240531", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), endOfDay, ""},
+		{"?from=2024-05-01&to=2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 23, 59, 59, 999999999, time.UTC), ""},
+		{"?from=2024-05-01&to=2024-05-31T10:00:00", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 31, 10, 0, 0, 0, time.UTC), ""},
+		{"?from=2024-06-01&to=2024-05-31", time.Time{}, time.Time{}, errors.ErrCodeValidationError},
+		{"?from=x", time.Time{}, time.Time{}, errors.ErrCodeParameterIllegalState},
+	}
+
+	for _, tt := range tests {
+		cc := newTestContext("/"+tt.haveQuery, nil)
+		got, err := cc.QueryTimeRange("from", "to")
+		if tt.wantCode != "" {
+			assertErrorCode(t, tt.wantCode, err, tt.haveQuery)
+			continue
+		}
+		assert.NoError(t, err, tt.haveQuery)
+		assert.Equal(t, tt.wantFrom, got.From, tt.haveQuery)
+		assert.Equal(t, tt.wantTo, got.To, tt.haveQuery)
+	}
+}
+
+func TestFilterTime(t *testing.T) {
+	tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+	SetTimeConfig(TimeConfig{Location: tokyo})
+	defer SetTimeConfig(DefaultTimeConfig)
+
+	config := filter.Config{Fields: map[string]filter.Field{"created_at": {Type: filter.TypeTime}}}
+	cc := newTestContext("/?created_at[gte]=2024-05-01+10:00:00&created_at[lte]=2024-05-31", nil)
+	f, err := cc.Filter(config)
+	assert.NoError(t, err)
+	assert.Equal(t, []filter.Condition{
+		{Column: "created_at", Operator: filter.OpGte, Value: time.Date(2024, 5, 1, 10, 0, 0, 0, tokyo)},
+		{Column: "created_at", Operator: filter.OpLte, Value: time.Date(2024, 5, 31, 23, 59, 59, 999999999, tokyo)},
+	}, f.Conditions)
+}
@@ -0,0 +1,95 @@
+package context
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rakutentech/go-echo-kit/errors"
+	"github.com/rakutentech/go-echo-kit/messages"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// defaultValidator reports JSON names of fields, which are also keys of messages like "email.required"
+var defaultValidator = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// BindAndValidate binds path params, body (JSON, XML or form) and query of GET and DELETE into req as echo does,
+// and validates it with
+// validator of echo, or validator.v9 when echo has no validator.
+// Validation failures return errors.Error with ErrCodeValidationError, whose errors.FieldErrors have localized messages
+func (cc *CustomContext) BindAndValidate(req interface{}) error {
+	if err := cc.Bind(req); err != nil {
+		if he, ok := err.(*echo.HTTPError); ok && he.Code != http.StatusInternalServerError {
+			return errors.Wrap(errors.ErrCodeParameterIllegalState, err, fmt.Sprint(he.Message))
+		}
+		return err
+	}
+
+	var err error
+	if v := cc.Echo().Validator; v != nil {
+		err = v.Validate(req)
+	} else {
+		err = defaultValidator.Struct(req)
+	}
+	if err == nil {
+		return nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		if _, ok := errors.FieldErrorsOf(err); ok {
+			return err
+		}
+		return errors.NewError(errors.ErrCodeValidationError, err)
+	}
+	return errors.NewValidationError(cc.fieldErrors(validationErrors))
+}
+
+func (cc *CustomContext) fieldErrors(validationErrors validator.ValidationErrors) errors.FieldErrors {
+	var message *messages.Message
+	if cc.Get(messages.LocalizerKey) != nil || messages.LocalizerFrom(cc.Request().Context()) != nil {
+		m := messages.NewMessage(cc)
+		message = &m
+	}
+
+	fieldErrors := make(errors.FieldErrors, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fieldError := errors.FieldError{Field: fe.Field(), Tag: fe.Tag(), Param: fe.Param()}
+		if message != nil {
+			fieldError.Message = message.GetFieldTagMessage(fe, map[string]interface{}{
+				"Field": fe.Field(),
+				"Param": fe.Param(),
+				"Value": fe.Value(),
+			})
+		}
+		if fieldError.Message == "" {
+			fieldError.Message = defaultFieldMessage(fe)
+		}
+		fieldErrors = append(fieldErrors, fieldError)
+	}
+	return fieldErrors
+}
+
+func defaultFieldMessage(fe validator.FieldError) string {
+	if fe.Param() == "" {
+		return fmt.Sprintf("%s failed on the '%s' tag", fe.Field(), fe.Tag())
+	}
+	return fmt.Sprintf("%s failed on the '%s=%s' tag", fe.Field(), fe.Tag(), fe.Param())
+}
@@ -0,0 +1,95 @@
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/rakutentech/go-echo-kit/errors"
+	"github.com/rakutentech/go-echo-kit/messages"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+type createUserRequest struct {
+	Name  string `json:"name" validate:"required,min=3"`
+	Email string `json:"email" validate:"required,email"`
+	Age   int    `json:"age" validate:"gte=0"`
+}
+
+func newJSONContext(body string, localizer *i18n.Localizer) *CustomContext {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if localizer != nil {
+		req = req.WithContext(messages.WithLocalizer(req.Context(), localizer))
+	}
+	return From(echo.New().NewContext(req, httptest.NewRecorder()))
+}
+
+func newTestLocalizer() *i18n.Localizer {
+	bundle := i18n.NewBundle(language.English)
+	bundle.MustAddMessages(language.English,
+		&i18n.Message{ID: "email.required", Other: "Email address is required"},
+		&i18n.Message{ID: "min", Other: "{{.Field}} must be at least {{.Param}} characters"},
+	)
+	return i18n.NewLocalizer(bundle, "en")
+}
+
+func TestBindAndValidate(t *testing.T) {
+	tests := []struct {
+		haveBody  string
+		wantCode  errors.ErrorCode
+		wantError errors.FieldErrors
+	}{
+		{`{"name":"alice","email":"alice@example.com"}`, "", nil},
+		{`{"name":"al"}`, errors.ErrCodeValidationError, errors.FieldErrors{
+			{Field: "name", Tag: "min", Param: "3", Message: "name must be at least 3 characters"},
+			{Field: "email", Tag: "required", Message: "Email address is required"},
+		}},
+		{`{"name":"alice","email":"alice","age":-1}`, errors.ErrCodeValidationError, errors.FieldErrors{
+			{Field: "email", Tag: "email", Message: "email failed on the 'email' tag"},
+			{Field: "age", Tag: "gte", Param: "0", Message: "age failed on the 'gte=0' tag"},
+		}},
+		{`{"name":`, errors.ErrCodeParameterIllegalState, nil},
+	}
+
+	for _, tt := range tests {
+		req := createUserRequest{}
+		err := newJSONContext(tt.haveBody, newTestLocalizer()).BindAndValidate(&req)
+		if tt.wantCode == "" {
+			assert.NoError(t, err, tt.haveBody)
+			continue
+		}
+		assertErrorCode(t, tt.wantCode, err, tt.haveBody)
+		fieldErrors, _ := errors.FieldErrorsOf(err)
+		assert.Equal(t, tt.wantError, fieldErrors, tt.haveBody)
+	}
+}
+
+func TestBindAndValidateEchoLocalizer(t *testing.T) {
+	cc := newJSONContext(`{"name":"alice"}`, nil)
+	cc.Set(messages.LocalizerKey, newTestLocalizer())
+
+	fieldErrors, _ := errors.FieldErrorsOf(cc.BindAndValidate(&createUserRequest{}))
+	assert.Equal(t, errors.FieldErrors{{Field: "email", Tag: "required", Message: "Email address is required"}}, fieldErrors)
+}
+
+func TestBindAndValidateBindError(t *testing.T) {
+	err := newJSONContext(`{"name":`, nil).BindAndValidate(&createUserRequest{})
+
+	var he *echo.HTTPError
+	assert.True(t, errors.As(err, &he))
+	assert.Equal(t, http.StatusBadRequest, he.Code)
+}
+
+func TestBindAndValidateWithoutLocalizer(t *testing.T) {
+	err := newJSONContext(`{"name":"alice"}`, nil).BindAndValidate(&createUserRequest{})
+
+	fieldErrors, ok := errors.FieldErrorsOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, errors.FieldErrors{{Field: "email", Tag: "required", Message: "email failed on the 'required' tag"}}, fieldErrors)
+	assert.Equal(t, "email: email failed on the 'required' tag", err.Error())
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Balancer selects which slave to use among n slaves
+// Implementations must be safe for concurrent use
+type Balancer interface {
+	Next(n int) int
+}
+
+// RoundRobinBalancer selects slaves in turn with lock-free counter
+type RoundRobinBalancer struct {
+	counter uint64 // keep first for 64-bit alignment of atomic operations
+}
+
+// NewRoundRobinBalancer ...
+func NewRoundRobinBalancer() *RoundRobinBalancer {
+	return &RoundRobinBalancer{}
+}
+
+// Next ...
+func (b *RoundRobinBalancer) Next(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return int((atomic.AddUint64(&b.counter, 1) - 1) % uint64(n))
+}
+
+// WeightedBalancer selects slaves in proportion to their weights with lock-free counter
+// A slave with weight 0 is never selected
+type WeightedBalancer struct {
+	counter uint64 // keep first for 64-bit alignment of atomic operations
+	weights []int
+	slots   []int
+}
+
+// NewWeightedBalancer creates balancer with weight per slave. Order is same as slaves
+// It returns an error for negative weights or when no slave has positive weight
+func NewWeightedBalancer(weights []int) (*WeightedBalancer, error) {
+	positive := false
+	for i, w := range weights {
+		if w < 0 {
+			return nil, fmt.Errorf("weight of slave %d is negative: %d", i, w)
+		}
+		if w > 0 {
+			positive = true
+		}
+	}
+	if !positive {
+		return nil, fmt.Errorf("no slave has positive weight: %v", weights)
+	}
+
+	// copy, so that caller can not change weights after slots are made
+	weights = append([]int(nil), weights...)
+	return &WeightedBalancer{
+		weights: weights,
+		slots:   weightedSlots(reduceWeights(weights)),
+	}, nil
+}
+
+// Len returns number of slaves weights are given for
+func (b *WeightedBalancer) Len() int {
+	return len(b.weights)
+}
+
+// Next ...
+// When slaves are fewer than weights, slaves without weight are skipped. Slaves more than weights are never selected
+func (b *WeightedBalancer) Next(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	counter := atomic.AddUint64(&b.counter, 1) - 1
+	for i := uint64(0); i < uint64(len(b.slots)); i++ {
+		if slot := b.slots[(counter+i)%uint64(len(b.slots))]; slot < n {
+			return slot
+		}
+	}
+	return 0
+}
+
+// weightedSlots spreads slave indexes over a cycle using smooth weighted round robin,
+// so that heavy slave is not selected many times in a row
+func weightedSlots(weights []int) []int {
+	total := 0
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+
+	slots := make([]int, 0, total)
+	current := make([]int, len(weights))
+	for len(slots) < total {
+		best := -1
+		for i, w := range weights {
+			if w <= 0 {
+				continue
+			}
+			current[i] += w
+			if best < 0 || current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		slots = append(slots, best)
+	}
+	return slots
+}
+
+// reduceWeights divides weights by their greatest common divisor to keep slots short
+func reduceWeights(weights []int) []int {
+	divisor := 0
+	for _, w := range weights {
+		divisor = gcd(divisor, w)
+	}
+	if divisor <= 1 {
+		return weights
+	}
+
+	reduced := make([]int, len(weights))
+	for i, w := range weights {
+		reduced[i] = w / divisor
+	}
+	return reduced
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundRobinBalancer(t *testing.T) {
+	b := NewRoundRobinBalancer()
+
+	var have []int
+	for i := 0; i < 6; i++ {
+		have = append(have, b.Next(3))
+	}
+	assert.Equal(t, []int{0, 1, 2, 0, 1, 2}, have)
+	assert.Equal(t, 0, b.Next(1))
+}
+
+func TestWeightedBalancer(t *testing.T) {
+	tests := []struct {
+		haveWeights []int
+		wantCounts  []int
+	}{
+		{[]int{1, 1, 1}, []int{4, 4, 4}},
+		{[]int{2, 1, 1}, []int{6, 3, 3}},
+		{[]int{3, 0, 1}, []int{9, 0, 3}},
+		{[]int{400, 200, 200}, []int{6, 3, 3}},
+		{[]int{1, 1}, []int{6, 6, 0}}, // slave without weight is not selected
+	}
+
+	for _, test := range tests {
+		b, err := NewWeightedBalancer(test.haveWeights)
+		assert.NoError(t, err)
+		haveCounts := make([]int, 3)
+		for i := 0; i < 12; i++ {
+			haveCounts[b.Next(3)]++
+		}
+		assert.Equal(t, test.wantCounts, haveCounts, test.haveWeights)
+	}
+}
+
+func TestNewWeightedBalancerCopy(t *testing.T) {
+	weights := []int{0, 1}
+	b, err := NewWeightedBalancer(weights)
+	assert.NoError(t, err)
+	weights[0], weights[1] = 1, 0
+	assert.Equal(t, 1, b.Next(2))
+	assert.Equal(t, 2, b.Len())
+
+	// weight without slave is skipped
+	b, err = NewWeightedBalancer([]int{1, 1, 4})
+	assert.NoError(t, err)
+	for i := 0; i < 12; i++ {
+		assert.Less(t, b.Next(2), 2)
+	}
+}
+
+func TestNewWeightedBalancerError(t *testing.T) {
+	for _, weights := range [][]int{nil, {0, 0}, {1, -1}} {
+		_, err := NewWeightedBalancer(weights)
+		assert.Error(t, err, weights)
+	}
+}
+
+func TestWeightedSlots(t *testing.T) {
+	assert.Equal(t, []int{0, 1, 0, 2, 0}, weightedSlots([]int{3, 1, 1}))
+	assert.Empty(t, weightedSlots([]int{0, 0}))
+}
+
+func TestReduceWeights(t *testing.T) {
+	assert.Equal(t, []int{3, 1, 0}, reduceWeights([]int{300, 100, 0}))
+	assert.Equal(t, []int{3, 2}, reduceWeights([]int{3, 2}))
+}
+
+func TestSlaveConn(t *testing.T) {
+	master, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer master.Close()
+	slaves := []*gorm.DB{{}, {}}
+
+	// master without slaves, which rejects writes
+	m := &Manager{Master: master}
+	readOnly, ok := m.SlaveConn().Get(readOnlyKey)
+	assert.True(t, ok)
+	assert.Equal(t, true, readOnly)
+
+	m.Slaves = slaves
+	assert.NoError(t, m.SetSlaveWeights([]int{0, 1}))
+	assert.Same(t, slaves[1], m.SlaveConn())
+	assert.Same(t, slaves[1], m.SlaveConn())
+
+	// invalid weights keep current balancer
+	assert.Error(t, m.SetSlaveWeights([]int{0, 0}))
+	assert.Error(t, m.SetSlaveWeights([]int{1}))
+	assert.Error(t, m.SetSlaveWeights([]int{1, 1, 1}))
+	assert.Same(t, slaves[1], m.SlaveConn())
+}
+
+func TestSetBalancerRace(t *testing.T) {
+	m := &Manager{Slaves: make([]*gorm.DB, 2)}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			m.SetBalancer(NewRoundRobinBalancer())
+			assert.NoError(t, m.SetSlaveWeights([]int{1, 2}))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			m.SlaveConn()
+		}
+	}()
+	wg.Wait()
+}
+
+func benchmarkManager() *Manager {
+	m := &Manager{Slaves: make([]*gorm.DB, 4)}
+	m.SetBalancer(NewRoundRobinBalancer())
+	return m
+}
+
+// BenchmarkSlaveConnRand is the former implementation with global math/rand
+func BenchmarkSlaveConnRand(b *testing.B) {
+	m := benchmarkManager()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = m.Slaves[rand.Intn(len(m.Slaves))]
+		}
+	})
+}
+
+func BenchmarkSlaveConnRoundRobin(b *testing.B) {
+	m := benchmarkManager()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = m.SlaveConn()
+		}
+	})
+}
+
+func BenchmarkSlaveConnWeighted(b *testing.B) {
+	m := benchmarkManager()
+	if err := m.SetSlaveWeights([]int{3, 1, 1, 1}); err != nil {
+		b.Fatal(err)
+	}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = m.SlaveConn()
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type statementInfoKey struct{}
+
+// statementInfo carries what gorm logger does not know from callbacks to logger through statement context
+type statementInfo struct {
+	dbName string
+	role   string
+	sql    string // SQL with placeholders
+}
+
+// statementCtx wraps statement context while a statement runs.
+// It is unwrapped by the next statement when a chain reuses the statement
+type statementCtx struct {
+	context.Context
+	parent context.Context
+	cancel context.CancelFunc
+	info   *statementInfo
+}
+
+// Value ...
+func (c *statementCtx) Value(key interface{}) interface{} {
+	if key == (statementInfoKey{}) {
+		return c.info
+	}
+	return c.Context.Value(key)
+}
+
+func statementInfoFrom(ctx context.Context) *statementInfo {
+	if ctx != nil {
+		if info, ok := ctx.Value(statementInfoKey{}).(*statementInfo); ok {
+			return info
+		}
+	}
+	return &statementInfo{}
+}
+
+// registerCallbacks registers kit callbacks to OpenDBConn connection
+func registerCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+	type register func(string, func(*gorm.DB)) error
+	starts := []register{
+		callbacks.Create().Before("*").Register,
+		callbacks.Query().Before("*").Register,
+		callbacks.Update().Before("*").Register,
+		callbacks.Delete().Before("*").Register,
+		callbacks.Raw().Before("*").Register,
+	}
+	ends := []register{
+		callbacks.Create().After("*").Register,
+		callbacks.Query().After("*").Register,
+		callbacks.Update().After("*").Register,
+		callbacks.Delete().After("*").Register,
+		callbacks.Raw().After("*").Register,
+		callbacks.Row().After("*").Register,
+	}
+
+	for _, start := range starts {
+		if err := start("echokit:statement_start", startStatement); err != nil {
+			return err
+		}
+	}
+	// Row and Rows are not bounded by query timeout since the rows are read after callbacks finish
+	if err := callbacks.Row().Before("*").Register("echokit:statement_start", startRowStatement); err != nil {
+		return err
+	}
+	for _, end := range ends {
+		if err := end("echokit:statement_end", endStatement); err != nil {
+			return err
+		}
+	}
+
+	if err := registerReadOnlyGuard(db); err != nil {
+		return err
+	}
+
+	markWrite := func(db *gorm.DB) {
+		if db.Error == nil {
+			MarkWrite(db.Statement.Context)
+		}
+	}
+	if err := callbacks.Create().After("gorm:create").Register("echokit:sticky_create", markWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("echokit:sticky_update", markWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("echokit:sticky_delete", markWrite); err != nil {
+		return err
+	}
+	// Exec runs Raw callbacks
+	return callbacks.Raw().After("gorm:raw").Register("echokit:sticky_raw", func(db *gorm.DB) {
+		if !isReadSQL(db.Statement.SQL.String()) {
+			markWrite(db)
+		}
+	})
+}
+
+func startStatement(db *gorm.DB) {
+	timeout := QueryTimeout()
+	if value, ok := db.Get(queryTimeoutKey); ok {
+		timeout, _ = value.(time.Duration)
+	}
+	wrapStatement(db, timeout)
+}
+
+func startRowStatement(db *gorm.DB) {
+	wrapStatement(db, 0)
+}
+
+// wrapStatement bounds statement by timeout and passes database name and role to logger
+func wrapStatement(db *gorm.DB, timeout time.Duration) {
+	parent := db.Statement.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	if ctx, ok := parent.(*statementCtx); ok {
+		parent = ctx.parent
+	}
+
+	ctx := &statementCtx{Context: parent, parent: parent, info: newStatementInfo(db)}
+	if timeout > 0 {
+		ctx.Context, ctx.cancel = context.WithTimeout(parent, timeout)
+	}
+	db.Statement.Context = ctx
+}
+
+// newStatementInfo resolves database name and role of statement from settings of GetConn
+func newStatementInfo(db *gorm.DB) *statementInfo {
+	info := &statementInfo{role: roleOf(ConnTypeMaster)}
+	if value, ok := db.Get(dbNameKey); ok {
+		info.dbName, _ = value.(string)
+	} else if len(databases) > 0 {
+		info.dbName = databases[0].Name
+	}
+	if value, ok := db.Get(connTypeKey); ok {
+		connType, _ := value.(ConnType)
+		info.role = roleOf(connType)
+	}
+	return info
+}
+
+func endStatement(db *gorm.DB) {
+	if ctx, ok := db.Statement.Context.(*statementCtx); ok {
+		ctx.info.sql = db.Statement.SQL.String()
+		if ctx.cancel != nil {
+			ctx.cancel()
+			// keep info for logger, but not the timeout for later use of the statement
+			db.Statement.Context = &statementCtx{Context: ctx.parent, parent: ctx.parent, info: ctx.info}
+		}
+	}
+}
+
+// sqlOperation returns the first keyword of SQL like SELECT
+func sqlOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 )
 
@@ -81,6 +82,11 @@ func (c *ConnStringBuilder) SetWithConfig(cfg *viper.Viper) *ConnStringBuilder {
 	c.Port = cfg.GetString("port")
 	c.Host = cfg.GetString("host")
 	c.Format = cfg.GetString("format")
+	if cfg.IsSet("options") {
+		if options, err := optionsFromConfig(cfg.Get("options")); err == nil {
+			c.Options = options
+		}
+	}
 	return c
 }
 
@@ -119,6 +125,24 @@ func generateDefaultOption() map[string]string {
 	return options
 }
 
+// optionsFromConfig accepts options either as a query string
+// ("charset=utf8&parseTime=True") or as a map. Viper lowercases map keys,
+// so the query string form should be used for case sensitive options.
+func optionsFromConfig(value interface{}) (map[string]string, error) {
+	options := make(map[string]string)
+	if str, ok := value.(string); ok {
+		query, err := url.ParseQuery(str)
+		if err != nil {
+			return nil, err
+		}
+		for k := range query {
+			options[k] = query.Get(k)
+		}
+		return options, nil
+	}
+	return cast.ToStringMapStringE(value)
+}
+
 func encodeOptions(Options map[string]string) string {
 	if len(Options) != 0 {
 		query := url.Values{}
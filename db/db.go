@@ -1,10 +1,11 @@
 package db
 
 import (
-	"math/rand"
+	"fmt"
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jinzhu/gorm"
@@ -14,6 +15,7 @@ import (
 
 var (
 	manager         *Manager
+	defaultBalancer = NewRoundRobinBalancer()
 	once            sync.Once
 	connStrings     []string
 	adminConnString string
@@ -35,6 +37,14 @@ type Manager struct {
 	Admin           *gorm.DB
 	Master          *gorm.DB
 	Slaves          []*gorm.DB
+	SQLLogger       SQLLoggerConfig
+
+	balancer atomic.Value // balancerValue, replaced while SlaveConn is called
+}
+
+// balancerValue keeps concrete type of atomic.Value same for any Balancer
+type balancerValue struct {
+	Balancer
 }
 
 // New returns singleton instance of DB manager
@@ -86,12 +96,15 @@ func setUpManager() *Manager {
 		}
 	}
 
-	return &Manager{
+	m := &Manager{
 		Driver:          driver,
 		ConnMaxLifetime: time.Second * time.Duration(maxLife),
 		MaxIdleConns:    maxIdleConn,
 		MaxOpenConns:    maxOpenConn,
+		SQLLogger:       DefaultSQLLoggerConfig,
 	}
+	m.SetBalancer(NewRoundRobinBalancer())
+	return m
 }
 
 // AddAdminConnString can add admin connect string
@@ -114,10 +127,10 @@ func (m *Manager) Open() *Manager {
 	if len(connStrings) == 0 {
 		panic("There is no connect string for DB. Please add them using AddConnString method")
 	}
-	m.Master = m.open(connStrings[0])
+	m.Master = m.open(connStrings[0], "master")
 	m.Slaves = make([]*gorm.DB, len(connStrings[1:]))
 	for i, connString := range connStrings[1:] {
-		m.Slaves[i] = m.open(connString)
+		m.Slaves[i] = m.open(connString, "slave").Set(readOnlyKey, true)
 	}
 	return m
 }
@@ -127,7 +140,7 @@ func (m *Manager) OpenAdmin() *Manager {
 	if adminConnString == "" {
 		panic("There is no admin connect string for DB. Please add them using AddAdminConnString method")
 	}
-	m.Admin = m.open(adminConnString)
+	m.Admin = m.open(adminConnString, "admin")
 	return m
 }
 
@@ -136,7 +149,7 @@ func (m *Manager) OpenMaster() *Manager {
 	if len(connStrings) == 0 {
 		panic("There is no connect string for DB. Please add them using AddConnString method")
 	}
-	m.Master = m.open(connStrings[0])
+	m.Master = m.open(connStrings[0], "master")
 	return m
 }
 
@@ -147,22 +160,47 @@ func (m *Manager) OpenSlaves() *Manager {
 	}
 	m.Slaves = make([]*gorm.DB, len(connStrings))
 	for i, connString := range connStrings {
-		m.Slaves[i] = m.open(connString)
+		m.Slaves[i] = m.open(connString, "slave").Set(readOnlyKey, true)
 	}
 	return m
 }
 
-func (m *Manager) open(connectString string) *gorm.DB {
-	instance, err := gorm.Open(m.Driver, connectString)
+func (m *Manager) open(connectString string, role string) *gorm.DB {
+	var source interface{} = connectString
+	if role == "slave" {
+		sqlDB, err := openReadOnlyDB(m.Driver, connectString)
+		if err != nil {
+			panic(err)
+		}
+		source = sqlDB
+	}
+	instance, err := gorm.Open(m.Driver, source)
 	if err != nil {
 		panic(err)
 	}
+	instance.SetLogger(sqlLoggerV1{config: m.SQLLogger, role: role})
+	registerReadOnlyGuardV1(instance)
 	instance.DB().SetConnMaxLifetime(m.ConnMaxLifetime)
 	instance.DB().SetMaxIdleConns(m.MaxIdleConns)
 	instance.DB().SetMaxOpenConns(m.MaxOpenConns)
 	return instance
 }
 
+// SetSQLLoggerConfig will change config of SQL logger which prints through the kit logger
+// SQL of each query is printed when log mode is true (see SetLogMode)
+func (m *Manager) SetSQLLoggerConfig(config SQLLoggerConfig) {
+	m.SQLLogger = config
+	if m.Admin != nil {
+		m.Admin.SetLogger(sqlLoggerV1{config: config, role: "admin"})
+	}
+	if m.Master != nil {
+		m.Master.SetLogger(sqlLoggerV1{config: config, role: "master"})
+	}
+	for _, slv := range m.Slaves {
+		slv.SetLogger(sqlLoggerV1{config: config, role: "slave"})
+	}
+}
+
 // SetLogMode will change SQL log mode (default: false)
 func (m *Manager) SetLogMode(logMode bool) {
 	m.Master.LogMode(logMode)
@@ -201,11 +239,59 @@ func (m *Manager) MasterConn() *gorm.DB {
 	return m.Master
 }
 
+// SetBalancer will change how SlaveConn selects slaves (default: round robin)
+// It is safe to call while SlaveConn is called
+func (m *Manager) SetBalancer(balancer Balancer) {
+	m.balancer.Store(balancerValue{balancer})
+}
+
+// Balancer returns balancer of SlaveConn
+func (m *Manager) Balancer() Balancer {
+	if value, ok := m.balancer.Load().(balancerValue); ok && value.Balancer != nil {
+		return value.Balancer
+	}
+	return defaultBalancer
+}
+
+// SetSlaveWeights will make SlaveConn select slaves in proportion to weights. Order is same as slaves
+// It returns an error of NewWeightedBalancer or when number of weights is not same as slaves, and keeps current balancer
+func (m *Manager) SetSlaveWeights(weights []int) error {
+	if len(weights) != len(m.Slaves) {
+		return fmt.Errorf("%d weights are given for %d slaves", len(weights), len(m.Slaves))
+	}
+	balancer, err := NewWeightedBalancer(weights)
+	if err != nil {
+		return err
+	}
+	m.SetBalancer(balancer)
+	return nil
+}
+
 // SlaveConn will return one of slave connection or master if all slave failed
 func (m *Manager) SlaveConn() *gorm.DB {
 	if l := len(m.Slaves); l > 0 {
-		return m.Slaves[rand.Intn(l)]
+		return m.Slaves[m.Balancer().Next(l)]
 	}
 
+	// derived from current master per call, so that it follows SetLogMode and reassigned Master
+	if m.Master != nil {
+		return m.Master.Set(readOnlyKey, true)
+	}
 	return m.MasterConn()
 }
+
+// registerReadOnlyGuardV1 rejects Create, Update and Delete on slave connections of SlaveConn
+// Raw writes by Exec are rejected by connections of slaves (see openReadOnlyDB), but not by master without slaves
+func registerReadOnlyGuardV1(instance *gorm.DB) {
+	guard := func(operation string) func(*gorm.Scope) {
+		return func(scope *gorm.Scope) {
+			if readOnly, ok := scope.Get(readOnlyKey); ok && readOnly == true && ReadOnlyGuard() {
+				scope.Err(newReadOnlyError(operation))
+			}
+		}
+	}
+	callbacks := instance.Callback()
+	callbacks.Create().Before("gorm:begin_transaction").Register("echokit:read_only_create", guard("create"))
+	callbacks.Update().Before("gorm:begin_transaction").Register("echokit:read_only_update", guard("update"))
+	callbacks.Delete().Before("gorm:begin_transaction").Register("echokit:read_only_delete", guard("delete"))
+}
@@ -8,14 +8,41 @@ import (
 	gormlogger "gorm.io/gorm/logger"
 	"gorm.io/plugin/dbresolver"
 
-	"log"
+	"context"
+	"fmt"
 	"os"
 	"sync"
-	"time"
 )
 
 var onceGormDB sync.Once
 var gormDB *gorm.DB
+var databases []Database
+
+// ErrNotOpened is set to connections from GetConn before OpenDBConn
+var ErrNotOpened error = unavailableError("database connection is not opened. Please open it using OpenDBConn")
+
+// ErrUnknownDatabase is set to connections from GetConn with name which OpenDBConn did not register
+var ErrUnknownDatabase error = unavailableError("unknown database")
+
+// unavailableError is translated into SQL_IllegalState by dberrors.FromDB
+type unavailableError string
+
+func (err unavailableError) Error() string {
+	return string(err)
+}
+
+// Unavailable ...
+func (err unavailableError) Unavailable() bool {
+	return true
+}
+
+// Database represents for database registered by OpenDBConn
+type Database struct {
+	Name     string
+	Default  bool // the database which gorm uses without dbresolver.Use
+	Masters  int
+	Replicas int
+}
 
 type ConnType int64
 
@@ -39,23 +66,19 @@ func OpenDBConn(conf[] MultiDbConf) *gorm.DB {
 	}
 
 	onceGormDB.Do(func() {
+		if err := initQueryTimeout(); err != nil {
+			logger.LogCritf("[Fatal Error]can not read query timeout: %v", err)
+		}
+
 		enableSqlLog := os.Getenv("SQL_LOGGER_ENABLED")
 
 		gormConfig := &gorm.Config{}
 
-		// print Slow SQL and happening errors
-		if enableSqlLog == "true" {
-			sqlLogger := gormlogger.New(
-				log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-				gormlogger.Config{
-					SlowThreshold: time.Second,   	  // Slow SQL threshold
-					LogLevel:      gormlogger.Error, // Log level
-					Colorful:      false,           // Disable color
-				},
-			)
-			gormConfig = &gorm.Config{
-				Logger: sqlLogger,
-			}
+		// print Slow SQL and happening errors through the kit logger
+		if sqlLogger != nil {
+			gormConfig.Logger = sqlLogger
+		} else if enableSqlLog == "true" {
+			gormConfig.Logger = NewSQLLogger(sqlLoggerConfigFromEnv())
 		}
 
 		/** default DB connection **/
@@ -99,7 +122,16 @@ func OpenDBConn(conf[] MultiDbConf) *gorm.DB {
 		if err != nil {
 			logger.LogCritf("[Fatal Error]can not connect to DB: %v", err)
 		}
+
+		err = registerCallbacks(DB)
+		if err != nil {
+			logger.LogCritf("[Fatal Error]can not register callbacks: %v", err)
+		}
 		gormDB = DB
+		databases = make([]Database, len(conf))
+		for idx, c := range conf {
+			databases[idx] = Database{Name: c.DbName, Default: idx == 0, Masters: 1, Replicas: len(c.Slaves)}
+		}
 	})
 
 	return gormDB
@@ -118,15 +150,60 @@ func CloseDBConn(dbConn *gorm.DB) {
 	}
 }
 
+// Databases returns databases registered by OpenDBConn
+func Databases() []Database {
+	return append([]Database(nil), databases...)
+}
+
 // GetConn get master or slave connection from DB i
+// The connection has ErrNotOpened or ErrUnknownDatabase as Error when DB i is not available,
+// and the error is returned by its queries
 func GetConn(DBName string, connType ConnType) *gorm.DB {
+	if gormDB == nil {
+		return errorConn(ErrNotOpened)
+	}
+	if !isRegistered(DBName) {
+		return errorConn(fmt.Errorf("%w: %s", ErrUnknownDatabase, DBName))
+	}
+
 	appDebug := os.Getenv("APP_DEBUG")
 	operation := dbresolver.Read; if connType == ConnTypeMaster {
 		operation = dbresolver.Write
 	}
 
+	conn := gormDB.Clauses(dbresolver.Use(DBName), operation).
+		Set(dbNameKey, DBName).
+		Set(connTypeKey, connType)
 	if appDebug =="true" {
-		return gormDB.Clauses(dbresolver.Use(DBName), operation).Debug()
+		return conn.Debug()
+	}
+	return conn
+}
+
+func isRegistered(DBName string) bool {
+	for _, database := range databases {
+		if database.Name == DBName {
+			return true
+		}
+	}
+	return false
+}
+
+// errorConn returns connection which does nothing but returning err
+func errorConn(err error) *gorm.DB {
+	var conn *gorm.DB
+	if gormDB == nil {
+		conn, _ = gorm.Open(nil, &gorm.Config{Logger: gormlogger.Discard})
+	} else {
+		conn = gormDB.Session(&gorm.Session{})
 	}
-	return gormDB.Clauses(dbresolver.Use(DBName), operation)
-}
\ No newline at end of file
+	_ = conn.AddError(err)
+	return conn
+}
+
+// GetConnCtx get master or slave connection from DB with context
+// Queries are cancelled with ctx and limited by query timeout (see SetQueryTimeout),
+// and slave reads go to master when request in ctx recently wrote (see StickyMiddleware)
+func GetConnCtx(ctx context.Context, DBName string, connType ConnType) *gorm.DB {
+	return GetConn(DBName, ResolveConnType(ctx, connType)).WithContext(ctx)
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// MultiDbConfFromConfig builds MultiDbConf set from config tree of named databases.
+// Each database has a master block and a list of replica blocks, e.g.
+//
+//	databases:
+//	  db1:
+//	    default: true
+//	    master:
+//	      host: master.db1
+//	      port: 3306
+//	      username: user
+//	      password: ${DB1_PASSWORD}
+//	      options: "charset=utf8mb4&parseTime=True&loc=Local"
+//	    replicas:
+//	      - host: replica1.db1
+//	        port: 3306
+//
+// The database marked as default comes first (see OpenDBConn), others follow in name order.
+// dbname of each block falls back to the database name.
+func MultiDbConfFromConfig(cfg *viper.Viper) ([]MultiDbConf, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("databases config is empty")
+	}
+
+	names := make([]string, 0)
+	for name := range cfg.AllSettings() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var confs []MultiDbConf
+	defaultIdx := -1
+	for _, name := range names {
+		dbCfg := cfg.Sub(name)
+		if dbCfg == nil {
+			return nil, fmt.Errorf("database %s: config must be a map", name)
+		}
+
+		conf, err := multiDbConfFromConfig(name, dbCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		if dbCfg.GetBool("default") {
+			if defaultIdx >= 0 {
+				return nil, fmt.Errorf("database %s: default database is already set to %s", name, confs[defaultIdx].DbName)
+			}
+			defaultIdx = len(confs)
+		}
+		confs = append(confs, conf)
+	}
+
+	if defaultIdx > 0 {
+		def := confs[defaultIdx]
+		copy(confs[1:defaultIdx+1], confs[:defaultIdx])
+		confs[0] = def
+	}
+
+	if err := ValidateMultiDbConf(confs); err != nil {
+		return nil, err
+	}
+	return confs, nil
+}
+
+func multiDbConfFromConfig(name string, cfg *viper.Viper) (MultiDbConf, error) {
+	conf := MultiDbConf{DbName: name}
+
+	masterCfg := cfg.Sub("master")
+	if masterCfg == nil {
+		return conf, fmt.Errorf("database %s: master is not set", name)
+	}
+	master, err := buildConnString(name, masterCfg)
+	if err != nil {
+		return conf, fmt.Errorf("database %s: master: %v", name, err)
+	}
+	conf.Master = master
+
+	var replicas []interface{}
+	if cfg.IsSet("replicas") {
+		if replicas, err = cast.ToSliceE(cfg.Get("replicas")); err != nil {
+			return conf, fmt.Errorf("database %s: replicas must be a list", name)
+		}
+	}
+	for i, replica := range replicas {
+		replicaCfg := viper.New()
+		replicaMap, err := cast.ToStringMapE(replica)
+		if err != nil {
+			return conf, fmt.Errorf("database %s: replica[%d] must be a map", name, i)
+		}
+		if err := replicaCfg.MergeConfigMap(replicaMap); err != nil {
+			return conf, fmt.Errorf("database %s: replica[%d]: %v", name, i, err)
+		}
+		slave, err := buildConnString(name, replicaCfg)
+		if err != nil {
+			return conf, fmt.Errorf("database %s: replica[%d]: %v", name, i, err)
+		}
+		conf.Slaves = append(conf.Slaves, slave)
+	}
+	return conf, nil
+}
+
+func buildConnString(name string, cfg *viper.Viper) (string, error) {
+	if cfg.IsSet("options") {
+		if _, err := optionsFromConfig(cfg.Get("options")); err != nil {
+			return "", fmt.Errorf("options: %v", err)
+		}
+	}
+	builder := new(ConnStringBuilder).SetWithConfig(cfg)
+	if builder.Host == "" {
+		return "", fmt.Errorf("host is not set")
+	}
+	if builder.Format != "" && builder.Format != MySQL {
+		return "", fmt.Errorf("unsupported format %s (mysql only)", builder.Format)
+	}
+	if builder.Dbname == "" {
+		builder.SetDbname(name)
+	}
+	return builder.Build(), nil
+}
+
+// ValidateMultiDbConf checks MultiDbConf set before passing it to OpenDBConn
+func ValidateMultiDbConf(confs []MultiDbConf) error {
+	if len(confs) == 0 {
+		return fmt.Errorf("no database is configured")
+	}
+
+	names := make(map[string]bool)
+	for i, conf := range confs {
+		if conf.DbName == "" {
+			return fmt.Errorf("database[%d]: name is empty", i)
+		}
+		if names[conf.DbName] {
+			return fmt.Errorf("database %s: duplicated name", conf.DbName)
+		}
+		names[conf.DbName] = true
+
+		if conf.Master == "" {
+			return fmt.Errorf("database %s: master dsn is empty", conf.DbName)
+		}
+		for j, slave := range conf.Slaves {
+			if slave == "" {
+				return fmt.Errorf("database %s: replica[%d] dsn is empty", conf.DbName, j)
+			}
+		}
+	}
+	return nil
+}
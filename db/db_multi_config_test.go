@@ -0,0 +1,91 @@
+package db
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rakutentech/go-echo-kit/config"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiDbConfFromConfig(t *testing.T) {
+	cfg := viper.New()
+	cfg.SetConfigType("yaml")
+	cfg.SetConfigName("multi_db")
+	cfg.AddConfigPath("./testdata")
+	if err := cfg.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	confs, err := MultiDbConfFromConfig(cfg.Sub("databases"))
+	assert.NoError(t, err)
+	assert.Equal(t, []MultiDbConf{
+		{
+			Master: "user1:secret1@(master.db1:3306)/db1?charset=utf8&loc=Local&parseTime=True",
+			Slaves: []string{
+				"user1:secret1@(replica1.db1:3306)/db1?charset=utf8&loc=Local&parseTime=True",
+				"user1:secret1@(replica2.db1:3306)/db1?charset=utf8&loc=Local&parseTime=True",
+			},
+			DbName: "db1",
+		},
+		{
+			Master: "user2:secret2@(master.db2:3306)/db2_schema?charset=utf8mb4&parseTime=True",
+			DbName: "db2",
+		},
+	}, confs)
+}
+
+func TestMultiDbConfFromConfigWithEnv(t *testing.T) {
+	os.Setenv("CONFIG_PATH", "./testdata/config_env")
+	os.Setenv("ECHOKIT_TEST_DB1_PASSWORD", "env_secret")
+	defer os.Unsetenv("CONFIG_PATH")
+	defer os.Unsetenv("ECHOKIT_TEST_DB1_PASSWORD")
+	config.Refresh()
+
+	confs, err := MultiDbConfFromConfig(config.New().Sub("databases"))
+	assert.NoError(t, err)
+	assert.Equal(t, []MultiDbConf{{
+		Master: "user1:env_secret@(master.db1:3306)/db1?charset=utf8&loc=Local&parseTime=True",
+		Slaves: []string{"user1:env_secret@(replica1.db1:3306)/db1?charset=utf8&loc=Local&parseTime=True"},
+		DbName: "db1",
+	}}, confs)
+}
+
+func TestMultiDbConfFromConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		have map[string]interface{}
+	}{
+		{"empty", map[string]interface{}{}},
+		{"no master", map[string]interface{}{
+			"db1": map[string]interface{}{"replicas": []interface{}{}},
+		}},
+		{"no host", map[string]interface{}{
+			"db1": map[string]interface{}{"master": map[string]interface{}{"username": "user"}},
+		}},
+		{"unsupported format", map[string]interface{}{
+			"db1": map[string]interface{}{"master": map[string]interface{}{"host": "db1", "format": PostGres}},
+		}},
+		{"bad replica", map[string]interface{}{
+			"db1": map[string]interface{}{
+				"master":   map[string]interface{}{"host": "db1"},
+				"replicas": []interface{}{map[string]interface{}{"port": "3306"}},
+			},
+		}},
+		{"bad options", map[string]interface{}{
+			"db1": map[string]interface{}{"master": map[string]interface{}{"host": "db1", "options": "charset=%zz"}},
+		}},
+		{"two defaults", map[string]interface{}{
+			"db1": map[string]interface{}{"default": true, "master": map[string]interface{}{"host": "db1"}},
+			"db2": map[string]interface{}{"default": true, "master": map[string]interface{}{"host": "db2"}},
+		}},
+	}
+
+	for _, test := range tests {
+		cfg := viper.New()
+		assert.NoError(t, cfg.MergeConfigMap(test.have))
+		_, err := MultiDbConfFromConfig(cfg)
+		assert.Error(t, err, test.name)
+	}
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestGetConnBeforeOpen(t *testing.T) {
+	assert.Nil(t, gormDB)
+	assert.Empty(t, Databases())
+
+	conn := GetConn("db1", ConnTypeSlave)
+	assert.True(t, errors.Is(conn.Error, ErrNotOpened))
+	assert.True(t, errors.Is(conn.Find(&tests.User{}).Error, ErrNotOpened))
+	assert.True(t, errors.Is(GetConnCtx(context.Background(), "db1", ConnTypeMaster).Error, ErrNotOpened))
+}
+
+func TestGetConn(t *testing.T) {
+	conn, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	assert.NoError(t, err)
+
+	gormDB = conn
+	databases = []Database{
+		{Name: "db1", Default: true, Masters: 1, Replicas: 2},
+		{Name: "db2", Masters: 1},
+	}
+	defer func() {
+		gormDB = nil
+		databases = nil
+	}()
+
+	assert.Equal(t, databases, Databases())
+
+	assert.NoError(t, GetConn("db1", ConnTypeSlave).Find(&tests.User{}).Error)
+	assert.NoError(t, GetConn("db2", ConnTypeMaster).Find(&tests.User{}).Error)
+
+	unknown := GetConn("typo", ConnTypeSlave)
+	assert.True(t, errors.Is(unknown.Error, ErrUnknownDatabase))
+	assert.EqualError(t, unknown.Find(&tests.User{}).Error, "unknown database: typo")
+
+	// error does not leak to other connections
+	assert.NoError(t, GetConn("db1", ConnTypeSlave).Find(&tests.User{}).Error)
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"github.com/rakutentech/go-echo-kit/errors/dberrors"
+
+	"gorm.io/gorm"
+)
+
+// ErrorPlugin is gorm plugin which translates db.Error into errors.Error by dberrors.FromDB
+// Usage dbConn.Use(db.NewErrorPlugin())
+type ErrorPlugin struct{}
+
+// NewErrorPlugin ...
+func NewErrorPlugin() *ErrorPlugin {
+	return &ErrorPlugin{}
+}
+
+// Name ...
+func (p *ErrorPlugin) Name() string {
+	return "echokit:errors"
+}
+
+// Initialize ...
+func (p *ErrorPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("*").Register("echokit:errors_create", translateError); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("*").Register("echokit:errors_query", translateError); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("*").Register("echokit:errors_update", translateError); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("*").Register("echokit:errors_delete", translateError); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("*").Register("echokit:errors_row", translateError); err != nil {
+		return err
+	}
+	return callbacks.Raw().After("*").Register("echokit:errors_raw", translateError)
+}
+
+// translateError keeps the cause, so errors.Is(err, gorm.ErrRecordNotFound) still works
+func translateError(db *gorm.DB) {
+	if db.Error != nil {
+		db.Error = dberrors.FromDB(db.Error)
+	}
+}
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/rakutentech/go-echo-kit/errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestErrorPlugin(t *testing.T) {
+	conn := openDryRunDB(t, nil)
+	assert.NoError(t, conn.Use(NewErrorPlugin()))
+
+	driverErr := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'alice' for key 'name'"}
+	assert.NoError(t, conn.Callback().Create().Register("test:duplicate", func(db *gorm.DB) {
+		_ = db.AddError(driverErr)
+	}))
+	assert.NoError(t, conn.Callback().Query().Register("test:not_found", func(db *gorm.DB) {
+		_ = db.AddError(gorm.ErrRecordNotFound)
+	}))
+
+	err := conn.Create(&guardUser{Name: "alice"}).Error
+	assert.Equal(t, errors.ErrCodeAlreadyExistsInDB, errors.CodeOf(err))
+	assert.True(t, errors.Is(err, driverErr))
+
+	err = conn.First(&guardUser{}).Error
+	assert.Equal(t, errors.ErrCodeNotExistInDB, errors.CodeOf(err))
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const queryTimeoutKey = "echokit:query_timeout"
+
+// default per-query timeout in nanoseconds for OpenDBConn connections, 0 means no timeout, -1 means not set yet
+var queryTimeout int64 = -1
+
+// SetQueryTimeout will change default per-query timeout (default: DB_QUERY_TIMEOUT or no timeout)
+func SetQueryTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&queryTimeout, int64(timeout))
+}
+
+// QueryTimeout returns default per-query timeout
+func QueryTimeout() time.Duration {
+	timeout := atomic.LoadInt64(&queryTimeout)
+	if timeout < 0 {
+		return 0
+	}
+	return time.Duration(timeout)
+}
+
+// WithQueryTimeout overrides default per-query timeout for the connection
+func WithQueryTimeout(conn *gorm.DB, timeout time.Duration) *gorm.DB {
+	return conn.Set(queryTimeoutKey, timeout)
+}
+
+// QueryTimeoutFromConfig reads query_timeout from config, or DB_QUERY_TIMEOUT when it is not set.
+// Both accept duration ("500ms", "3s") or seconds ("3")
+// Usage timeout, err := db.QueryTimeoutFromConfig(cfg.Sub("database")); db.SetQueryTimeout(timeout)
+func QueryTimeoutFromConfig(cfg *viper.Viper) (time.Duration, error) {
+	if cfg != nil && cfg.IsSet("query_timeout") {
+		return parseQueryTimeout("query_timeout", cfg.GetString("query_timeout"))
+	}
+	return queryTimeoutFromEnv()
+}
+
+func queryTimeoutFromEnv() (time.Duration, error) {
+	return parseQueryTimeout("DB_QUERY_TIMEOUT", os.Getenv("DB_QUERY_TIMEOUT"))
+}
+
+func parseQueryTimeout(name string, value string) (time.Duration, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return timeout, nil
+}
+
+// initQueryTimeout reads DB_QUERY_TIMEOUT unless SetQueryTimeout is called
+func initQueryTimeout() error {
+	if atomic.LoadInt64(&queryTimeout) >= 0 {
+		return nil
+	}
+	timeout, err := queryTimeoutFromEnv()
+	if err != nil {
+		return err
+	}
+	atomic.CompareAndSwapInt64(&queryTimeout, -1, int64(timeout))
+	return nil
+}
+
+// QueryContextConfig defines the config for query context middleware
+type QueryContextConfig struct {
+	// Skipper defines a function to skip middleware
+	Skipper middleware.Skipper
+
+	// Timeout bounds all queries of the request in total, 0 means no timeout
+	Timeout time.Duration
+}
+
+// DefaultQueryContextConfig ...
+var DefaultQueryContextConfig = QueryContextConfig{
+	Skipper: middleware.DefaultSkipper,
+}
+
+// QueryContextMiddleware cancels request context when handler returns
+// so that queries started with GetConnCtx(c.Request().Context(), ...) never outlive the request
+func QueryContextMiddleware() echo.MiddlewareFunc {
+	return QueryContextMiddlewareWithConfig(DefaultQueryContextConfig)
+}
+
+// QueryContextMiddlewareWithConfig cancels request context when handler returns or timeout passes.
+// Request context is also cancelled when the client disconnects
+func QueryContextMiddlewareWithConfig(config QueryContextConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultQueryContextConfig.Skipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			var ctx context.Context
+			var cancel context.CancelFunc
+			if config.Timeout > 0 {
+				ctx, cancel = context.WithTimeout(c.Request().Context(), config.Timeout)
+			} else {
+				ctx, cancel = context.WithCancel(c.Request().Context())
+			}
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestQueryTimeoutCallbacks(t *testing.T) {
+	conn, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	assert.NoError(t, err)
+	assert.NoError(t, registerCallbacks(conn))
+
+	var deadline time.Time
+	var hasDeadline bool
+	assert.NoError(t, conn.Callback().Query().Before("gorm:query").Register("test:deadline", func(db *gorm.DB) {
+		deadline, hasDeadline = db.Statement.Context.Deadline()
+	}))
+
+	defer SetQueryTimeout(0)
+	SetQueryTimeout(0)
+	conn.Find(&tests.User{})
+	assert.False(t, hasDeadline)
+
+	SetQueryTimeout(time.Minute)
+	chain := conn.WithContext(context.Background()).Where("id > ?", 0)
+	chain.Find(&tests.User{})
+	assert.True(t, hasDeadline)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	// statement reused by chain is not cancelled after query
+	chain.Find(&tests.User{})
+	assert.NoError(t, chain.Statement.Context.Err())
+	_, hasDeadline = chain.Statement.Context.Deadline()
+	assert.False(t, hasDeadline)
+
+	WithQueryTimeout(conn, time.Hour).Find(&tests.User{})
+	assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Second)
+}
+
+func TestQueryTimeoutFromConfig(t *testing.T) {
+	tests := []struct {
+		haveConfig  map[string]interface{}
+		haveEnv     string
+		wantTimeout time.Duration
+		wantErr     bool
+	}{
+		{nil, "", 0, false},
+		{nil, "3", 3 * time.Second, false},
+		{nil, "500ms", 500 * time.Millisecond, false},
+		{nil, "3x", 0, true},
+		{map[string]interface{}{"query_timeout": "2s"}, "3", 2 * time.Second, false},
+		{map[string]interface{}{"query_timeout": 5}, "", 5 * time.Second, false},
+		{map[string]interface{}{"query_timeout": "soon"}, "", 0, true},
+	}
+
+	defer os.Unsetenv("DB_QUERY_TIMEOUT")
+	for _, test := range tests {
+		os.Setenv("DB_QUERY_TIMEOUT", test.haveEnv)
+		cfg := viper.New()
+		assert.NoError(t, cfg.MergeConfigMap(test.haveConfig))
+		timeout, err := QueryTimeoutFromConfig(cfg)
+		assert.Equal(t, test.wantErr, err != nil, test.haveEnv)
+		assert.Equal(t, test.wantTimeout, timeout, test.haveEnv)
+	}
+}
+
+func TestInitQueryTimeout(t *testing.T) {
+	defer atomic.StoreInt64(&queryTimeout, atomic.LoadInt64(&queryTimeout))
+	defer os.Unsetenv("DB_QUERY_TIMEOUT")
+
+	atomic.StoreInt64(&queryTimeout, -1)
+	os.Setenv("DB_QUERY_TIMEOUT", "3x")
+	assert.Error(t, initQueryTimeout())
+	assert.Equal(t, time.Duration(0), QueryTimeout())
+
+	os.Setenv("DB_QUERY_TIMEOUT", "3")
+	assert.NoError(t, initQueryTimeout())
+	assert.Equal(t, 3*time.Second, QueryTimeout())
+
+	// SetQueryTimeout precedes DB_QUERY_TIMEOUT
+	SetQueryTimeout(time.Second)
+	assert.NoError(t, initQueryTimeout())
+	assert.Equal(t, time.Second, QueryTimeout())
+}
+
+func TestQueryContextMiddleware(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	var ctx context.Context
+	handler := func(c echo.Context) error {
+		ctx = c.Request().Context()
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		assert.NoError(t, ctx.Err())
+		return nil
+	}
+	mw := QueryContextMiddlewareWithConfig(QueryContextConfig{Timeout: time.Minute})
+	assert.NoError(t, mw(handler)(c))
+	assert.Equal(t, context.Canceled, ctx.Err())
+}
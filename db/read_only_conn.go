@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+)
+
+// openReadOnlyDB opens database whose connections reject writing SQL while the read-only guard is enabled.
+// It covers Exec of DB manager, which jinzhu/gorm runs without callbacks and without *sql.DB replaced.
+// SQL is not parsed while the guard is disabled, which is default in prod
+func openReadOnlyDB(driverName string, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	db.Close()
+
+	var connector driver.Connector = dsnConnector{dsn: dsn, driver: d}
+	if dc, ok := d.(driver.DriverContext); ok {
+		if connector, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(readOnlyConnector{connector: connector}), nil
+}
+
+// dsnConnector is the connector of drivers which do not implement driver.DriverContext
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+// Connect ...
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver ...
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type readOnlyConnector struct {
+	connector driver.Connector
+}
+
+// Connect ...
+func (c readOnlyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyConn{Conn: conn}, nil
+}
+
+// Driver ...
+func (c readOnlyConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// readOnlyConn checks SQL before the driver connection runs it.
+// It forwards optional interfaces of the driver connection, so that database/sql can ping, validate and reset it
+type readOnlyConn struct {
+	driver.Conn
+}
+
+func checkReadOnly(query string) error {
+	if ReadOnlyGuard() && !isReadSQL(query) {
+		return newReadOnlyError("raw write")
+	}
+	return nil
+}
+
+// Prepare ...
+func (c *readOnlyConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext ...
+func (c *readOnlyConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := checkReadOnly(query); err != nil {
+		return nil, err
+	}
+	if conn, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return conn.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// ExecContext ...
+func (c *readOnlyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := checkReadOnly(query); err != nil {
+		return nil, err
+	}
+	if conn, ok := c.Conn.(driver.ExecerContext); ok {
+		return conn.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// QueryContext ...
+func (c *readOnlyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := checkReadOnly(query); err != nil {
+		return nil, err
+	}
+	if conn, ok := c.Conn.(driver.QueryerContext); ok {
+		return conn.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// BeginTx ...
+func (c *readOnlyConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if conn, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return conn.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// CheckNamedValue ...
+func (c *readOnlyConn) CheckNamedValue(value *driver.NamedValue) error {
+	if conn, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return conn.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// ResetSession ...
+func (c *readOnlyConn) ResetSession(ctx context.Context) error {
+	if conn, ok := c.Conn.(driver.SessionResetter); ok {
+		return conn.ResetSession(ctx)
+	}
+	return nil
+}
+
+// Ping ...
+func (c *readOnlyConn) Ping(ctx context.Context) error {
+	if conn, ok := c.Conn.(driver.Pinger); ok {
+		return conn.Ping(ctx)
+	}
+	return nil
+}
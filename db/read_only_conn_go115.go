@@ -0,0 +1,14 @@
+//go:build go1.15
+// +build go1.15
+
+package db
+
+import "database/sql/driver"
+
+// IsValid ...
+func (c *readOnlyConn) IsValid() bool {
+	if conn, ok := c.Conn.(driver.Validator); ok {
+		return conn.IsValid()
+	}
+	return true
+}
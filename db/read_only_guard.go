@@ -0,0 +1,196 @@
+package db
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+
+	"gorm.io/gorm"
+)
+
+const readOnlyKey = "echokit:read_only"
+
+// 1: enabled, 0: disabled, -1: not set yet
+var readOnlyGuard int32 = -1
+
+// SetReadOnlyGuard will enable or disable the guard which rejects writes on slave connections
+// (default: DB_READ_ONLY_GUARD, or enabled unless APP_ENV is prod)
+func SetReadOnlyGuard(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&readOnlyGuard, value)
+}
+
+// ReadOnlyGuard returns true when writes on slave connections are rejected
+func ReadOnlyGuard() bool {
+	value := atomic.LoadInt32(&readOnlyGuard)
+	if value < 0 {
+		value = 0
+		if readOnlyGuardFromEnv() {
+			value = 1
+		}
+		atomic.CompareAndSwapInt32(&readOnlyGuard, -1, value)
+	}
+	return value == 1
+}
+
+func readOnlyGuardFromEnv() bool {
+	if env := os.Getenv("DB_READ_ONLY_GUARD"); len(env) != 0 {
+		return strings.ToLower(env) != "false"
+	}
+	return strings.ToLower(os.Getenv("APP_ENV")) != "prod"
+}
+
+func newReadOnlyError(operation string) errors.Error {
+	return errors.NewErrorWithMsgf(errors.ErrCodeSQLIllegalState, "%s is not allowed on slave connection. Please use master connection", operation)
+}
+
+// registerReadOnlyGuard rejects Create, Update, Delete and raw writes on slave connections of GetConn
+func registerReadOnlyGuard(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:begin_transaction").Register("echokit:read_only_create", guardReadOnly("create")); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:begin_transaction").Register("echokit:read_only_update", guardReadOnly("update")); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:begin_transaction").Register("echokit:read_only_delete", guardReadOnly("delete")); err != nil {
+		return err
+	}
+	return callbacks.Raw().Before("gorm:raw").Register("echokit:read_only_raw", func(db *gorm.DB) {
+		if !isReadSQL(db.Statement.SQL.String()) {
+			guardReadOnly("raw write")(db)
+		}
+	})
+}
+
+func guardReadOnly(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || !ReadOnlyGuard() {
+			return
+		}
+		if value, ok := db.Get(connTypeKey); ok && value == ConnTypeSlave {
+			_ = db.AddError(newReadOnlyError(operation))
+		}
+	}
+}
+
+// isReadSQL returns true for SQL which does not write.
+// SELECT and WITH are writes with INTO, FOR UPDATE, or a statement like WITH t AS (...) UPDATE
+func isReadSQL(sql string) bool {
+	words := sqlKeywords(sql)
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0].text {
+	case "SHOW", "DESCRIBE", "DESC", "SET", "USE":
+		return true
+	case "SELECT", "WITH":
+		return isReadStatement(words)
+	case "EXPLAIN":
+		// EXPLAIN ANALYZE runs the statement, so check the explained one
+		for i, word := range words[1:] {
+			if statementKeywords[word.text] && !word.call {
+				words[i+1].start = true
+				return isReadStatement(words[i+1:])
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// statementKeywords start statements. They are not writes as functions like REPLACE(name, 'a', 'b')
+var statementKeywords = map[string]bool{
+	"SELECT": true, "WITH": true, "INSERT": true, "UPDATE": true, "DELETE": true, "REPLACE": true, "MERGE": true,
+}
+
+func isReadStatement(words []sqlWord) bool {
+	for i, word := range words {
+		if word.call {
+			continue
+		}
+		switch word.text {
+		case "INSERT", "DELETE", "REPLACE", "MERGE":
+			if word.start {
+				return false
+			}
+		case "UPDATE":
+			// FOR UPDATE and FOR NO KEY UPDATE lock rows
+			if word.start || i > 0 && (words[i-1].text == "FOR" || words[i-1].text == "KEY") {
+				return false
+			}
+		case "INTO":
+			// SELECT ... INTO OUTFILE, DUMPFILE, @var or table
+			return false
+		}
+	}
+	return true
+}
+
+// sqlWord is upper case word of SQL
+type sqlWord struct {
+	text string
+	// start is true for the first word of statement or subquery, and the word after CTE like WITH t AS (...) UPDATE
+	start bool
+	// call is true for function call like REPLACE(...)
+	call bool
+}
+
+// sqlKeywords returns words of SQL, except for quoted strings, identifiers and comments
+func sqlKeywords(sql string) []sqlWord {
+	var words []sqlWord
+	// last token except for spaces and comments, 0 at the beginning
+	var last byte
+	for i := 0; i < len(sql); i++ {
+		switch ch := sql[i]; {
+		case ch == '\'' || ch == '"' || ch == '`':
+			for i++; i < len(sql) && sql[i] != ch; i++ {
+				if sql[i] == '\\' {
+					i++
+				}
+			}
+			last = ch
+		case ch == '-' && strings.HasPrefix(sql[i:], "--"), ch == '#':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case ch == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return words
+			}
+			i += end + 3
+		case isSQLWordChar(ch):
+			start := i
+			for i+1 < len(sql) && isSQLWordChar(sql[i+1]) {
+				i++
+			}
+			next := i + 1
+			for next < len(sql) && isSQLSpace(sql[next]) {
+				next++
+			}
+			words = append(words, sqlWord{
+				text:  strings.ToUpper(sql[start : i+1]),
+				start: last == 0 || last == '(' || last == ')' || last == ';',
+				call:  next < len(sql) && sql[next] == '(',
+			})
+			last = 'w'
+		case !isSQLSpace(ch):
+			last = ch
+		}
+	}
+	return words
+}
+
+func isSQLWordChar(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+func isSQLSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
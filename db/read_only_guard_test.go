@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	gormlogger "gorm.io/gorm/logger"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+type guardUser struct {
+	ID   uint
+	Name string
+}
+
+func assertReadOnlyError(t *testing.T, err error) {
+	if assert.Error(t, err) {
+		kitErr, ok := err.(errors.Error)
+		assert.True(t, ok)
+		assert.Equal(t, string(errors.ErrCodeSQLIllegalState), kitErr.ErrorCode())
+	}
+}
+
+// setReadOnlyGuard sets the guard, and returns a func which restores the previous value
+func setReadOnlyGuard(enabled bool) func() {
+	prev := atomic.LoadInt32(&readOnlyGuard)
+	SetReadOnlyGuard(enabled)
+	return func() { atomic.StoreInt32(&readOnlyGuard, prev) }
+}
+
+func TestReadOnlyGuard(t *testing.T) {
+	defer setReadOnlyGuard(true)()
+	gormDB = openDryRunDB(t, gormlogger.Discard)
+	databases = []Database{{Name: "db1", Default: true}}
+	defer func() {
+		gormDB = nil
+		databases = nil
+	}()
+
+	assertReadOnlyError(t, GetConn("db1", ConnTypeSlave).Create(&gormtests.User{Name: "test"}).Error)
+	assertReadOnlyError(t, GetConn("db1", ConnTypeSlave).Model(&gormtests.User{}).Where("id = ?", 1).Update("name", "test").Error)
+	assertReadOnlyError(t, GetConn("db1", ConnTypeSlave).Where("id = ?", 1).Delete(&gormtests.User{}).Error)
+	assertReadOnlyError(t, GetConn("db1", ConnTypeSlave).Exec("UPDATE users SET name = ?", "test").Error)
+
+	assert.NoError(t, GetConn("db1", ConnTypeSlave).Exec("SELECT 1").Error)
+	assert.NoError(t, GetConn("db1", ConnTypeSlave).Find(&gormtests.User{}).Error)
+	assert.NoError(t, GetConn("db1", ConnTypeMaster).Create(&gormtests.User{Name: "test"}).Error)
+
+	assertReadOnlyError(t, GetConn("db1", ConnTypeSlave).Exec("SELECT * FROM users FOR UPDATE").Error)
+
+	SetReadOnlyGuard(false)
+	assert.NoError(t, GetConn("db1", ConnTypeSlave).Create(&gormtests.User{Name: "test"}).Error)
+}
+
+func TestReadOnlyGuardManager(t *testing.T) {
+	defer setReadOnlyGuard(true)()
+	// errors are logged asynchronously by jinzhu/gorm, which should not be captured by other tests
+	m := &Manager{Driver: "sqlite3", MaxIdleConns: 1, MaxOpenConns: 1, SQLLogger: SQLLoggerConfig{LogLevel: gormlogger.Silent}} // single in-memory database
+	m.Master = m.open(":memory:", "master")
+	defer m.Close()
+	assert.NoError(t, m.MasterConn().AutoMigrate(&guardUser{}).Error)
+
+	// no slaves, falls back to master
+	assertReadOnlyError(t, m.SlaveConn().Create(&guardUser{Name: "test"}).Error)
+	assert.NoError(t, m.MasterConn().Create(&guardUser{Name: "test"}).Error)
+
+	// follows settings of master after setup
+	m.Master = m.Master.Set("echokit:test", true)
+	value, ok := m.SlaveConn().Get("echokit:test")
+	assert.True(t, ok)
+	assert.Equal(t, true, value)
+
+	m.Slaves = []*gorm.DB{m.Master.Set(readOnlyKey, true)}
+	user := guardUser{ID: 1, Name: "updated"}
+	assertReadOnlyError(t, m.SlaveConn().Save(&user).Error)
+	assertReadOnlyError(t, m.SlaveConn().Delete(&user).Error)
+
+	var users []guardUser
+	assert.NoError(t, m.SlaveConn().Find(&users).Error)
+	assert.Equal(t, []guardUser{{ID: 1, Name: "test"}}, users)
+}
+
+func TestReadOnlyGuardManagerExec(t *testing.T) {
+	defer setReadOnlyGuard(true)()
+	m := &Manager{Driver: "sqlite3", MaxIdleConns: 1, MaxOpenConns: 1, SQLLogger: SQLLoggerConfig{LogLevel: gormlogger.Silent}}
+	slave := m.open(":memory:", "slave").Set(readOnlyKey, true)
+	defer slave.Close()
+
+	assertReadOnlyError(t, slave.Exec("CREATE TABLE guard_users (id integer, name text)").Error)
+	assertReadOnlyError(t, slave.Exec("INSERT INTO guard_users (name) VALUES (?)", "test").Error)
+	assert.NoError(t, slave.Exec("SELECT 1").Error)
+	assert.NotNil(t, slave.DB())
+
+	SetReadOnlyGuard(false)
+	assert.NoError(t, slave.Exec("CREATE TABLE guard_users (id integer, name text)").Error)
+	assert.NoError(t, slave.Exec("INSERT INTO guard_users (name) VALUES (?)", "test").Error)
+
+	SetReadOnlyGuard(true)
+	var count int
+	assert.NoError(t, slave.Raw("SELECT COUNT(*) FROM guard_users WHERE name = ?", "test").Row().Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+// fakeConn records calls of optional driver interfaces
+type fakeConn struct {
+	driver.Conn
+	calls []string
+}
+
+func (c *fakeConn) Ping(context.Context) error {
+	c.calls = append(c.calls, "ping")
+	return driver.ErrBadConn
+}
+
+func (c *fakeConn) IsValid() bool {
+	c.calls = append(c.calls, "valid")
+	return false
+}
+
+func (c *fakeConn) ResetSession(context.Context) error {
+	c.calls = append(c.calls, "reset")
+	return nil
+}
+
+func TestReadOnlyConnForwards(t *testing.T) {
+	conn := &fakeConn{}
+	readOnly := &readOnlyConn{Conn: conn}
+
+	assert.Equal(t, driver.ErrBadConn, readOnly.Ping(context.Background()))
+	assert.False(t, readOnly.IsValid())
+	assert.NoError(t, readOnly.ResetSession(context.Background()))
+	assert.Equal(t, []string{"ping", "valid", "reset"}, conn.calls)
+
+	// connections without the interfaces
+	readOnly = &readOnlyConn{}
+	assert.NoError(t, readOnly.Ping(context.Background()))
+	assert.True(t, readOnly.IsValid())
+}
+
+func TestIsReadSQL(t *testing.T) {
+	tests := []struct {
+		haveSQL string
+		want    bool
+	}{
+		{"SELECT * FROM users", true},
+		{"  select name, updated_at FROM `update` WHERE name = 'DELETE'", true},
+		{"SELECT * FROM users /* FOR UPDATE */ -- INTO\n", true},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{"SHOW TABLES", true},
+		{"EXPLAIN SELECT * FROM users", true},
+		{"SET NAMES utf8mb4", true},
+		{"SELECT REPLACE(name, 'a', 'b') FROM users", true},
+		{"SELECT INSERT(name, 1, 2, 'x'), replace FROM users", true},
+		{"SELECT name AS `replace`, updated_at update_time FROM users", true},
+		{"SELECT * FROM users FOR SHARE", true},
+		{"SELECT * FROM (SELECT id FROM users) t", true},
+		{"SELECT * FROM users FOR UPDATE", false},
+		{"SELECT * INTO backup FROM users", false},
+		{"SELECT id INTO @id FROM users", false},
+		{"SELECT * FROM users INTO OUTFILE '/tmp/users'", false},
+		{"SELECT * FROM users FOR NO KEY UPDATE", false},
+		{"WITH t AS (DELETE FROM users RETURNING id) SELECT * FROM t", false},
+		{"WITH t AS (SELECT 1) UPDATE users SET name = 'a'", false},
+		{"WITH t AS (SELECT 1) DELETE FROM users", false},
+		{"EXPLAIN ANALYZE DELETE FROM users", false},
+		{"EXPLAIN SELECT REPLACE(name, 'a', 'b') FROM users", true},
+		{"INSERT INTO users (name) VALUES ('a')", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, isReadSQL(test.haveSQL), test.haveSQL)
+	}
+}
+
+func TestReadOnlyGuardFromEnv(t *testing.T) {
+	tests := []struct {
+		haveAppEnv string
+		haveEnv    string
+		want       bool
+	}{
+		{"", "", true},
+		{"stg", "", true},
+		{"prod", "", false},
+		{"PROD", "", false},
+		{"prod", "true", true},
+		{"prod", "false", false},
+		{"stg", "FALSE", false},
+	}
+
+	defer os.Unsetenv("APP_ENV")
+	defer os.Unsetenv("DB_READ_ONLY_GUARD")
+	for _, test := range tests {
+		os.Setenv("APP_ENV", test.haveAppEnv)
+		os.Setenv("DB_READ_ONLY_GUARD", test.haveEnv)
+		assert.Equal(t, test.want, readOnlyGuardFromEnv())
+	}
+}
@@ -0,0 +1,318 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rakutentech/go-echo-kit/logger"
+
+	"github.com/spf13/viper"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const (
+	dbNameKey   = "echokit:db_name"
+	connTypeKey = "echokit:conn_type"
+)
+
+// SQLLoggerConfig defines the config for SQL logger
+type SQLLoggerConfig struct {
+	// SlowThreshold logs queries slower than this as warning, 0 disables it
+	SlowThreshold time.Duration
+
+	// LogLevel is one of gorm logger levels. Info logs every query
+	LogLevel gormlogger.LogLevel
+
+	// RedactParams logs SQL with placeholders instead of parameter values
+	RedactParams bool
+
+	// IgnoreRecordNotFoundError does not log gorm.ErrRecordNotFound
+	IgnoreRecordNotFoundError bool
+}
+
+// DefaultSQLLoggerConfig ...
+var DefaultSQLLoggerConfig = SQLLoggerConfig{
+	SlowThreshold: time.Second,
+	LogLevel:      gormlogger.Error,
+}
+
+var sqlLogLevels = map[string]gormlogger.LogLevel{
+	"silent": gormlogger.Silent,
+	"error":  gormlogger.Error,
+	"warn":   gormlogger.Warn,
+	"info":   gormlogger.Info,
+}
+
+// SQLLoggerConfigFromConfig reads slow_threshold, level, redact_params and ignore_record_not_found from config
+func SQLLoggerConfigFromConfig(cfg *viper.Viper) SQLLoggerConfig {
+	config := DefaultSQLLoggerConfig
+	if cfg == nil {
+		return config
+	}
+
+	if cfg.IsSet("slow_threshold") {
+		config.SlowThreshold = cfg.GetDuration("slow_threshold")
+	}
+	if level, ok := sqlLogLevels[strings.ToLower(cfg.GetString("level"))]; ok {
+		config.LogLevel = level
+	}
+	config.RedactParams = cfg.GetBool("redact_params")
+	config.IgnoreRecordNotFoundError = cfg.GetBool("ignore_record_not_found")
+	return config
+}
+
+// SQL_LOGGER_SLOW_THRESHOLD, SQL_LOGGER_LEVEL and SQL_LOGGER_REDACT_PARAMS
+func sqlLoggerConfigFromEnv() SQLLoggerConfig {
+	config := DefaultSQLLoggerConfig
+
+	if env := os.Getenv("SQL_LOGGER_SLOW_THRESHOLD"); len(env) != 0 {
+		threshold, err := time.ParseDuration(env)
+		if err != nil {
+			panic(err)
+		}
+		config.SlowThreshold = threshold
+	}
+	if level, ok := sqlLogLevels[strings.ToLower(os.Getenv("SQL_LOGGER_LEVEL"))]; ok {
+		config.LogLevel = level
+	}
+	config.RedactParams = os.Getenv("SQL_LOGGER_REDACT_PARAMS") == "true"
+	return config
+}
+
+var sqlLogger gormlogger.Interface
+
+// SetSQLLogger will change SQL logger of OpenDBConn (default: SQL_LOGGER_* environment variables)
+// It must be called before OpenDBConn
+func SetSQLLogger(l gormlogger.Interface) {
+	sqlLogger = l
+}
+
+type kitSQLLogger struct {
+	config SQLLoggerConfig
+}
+
+// NewSQLLogger creates gorm logger which prints through the kit logger
+// with duration, rows affected, database name, role and context fields like request ID (see logger.ContextFields)
+func NewSQLLogger(config SQLLoggerConfig) gormlogger.Interface {
+	return &kitSQLLogger{config: config}
+}
+
+// LogMode ...
+func (l *kitSQLLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	config := l.config
+	config.LogLevel = level
+	return &kitSQLLogger{config: config}
+}
+
+// Info ...
+func (l *kitSQLLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.LogLevel >= gormlogger.Info {
+		logger.LogNoticef("[SQL] %s %s", logger.ContextFields(ctx), fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn ...
+func (l *kitSQLLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.LogLevel >= gormlogger.Warn {
+		logger.LogWarnf("[SQL] %s %s", logger.ContextFields(ctx), fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error ...
+func (l *kitSQLLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.LogLevel >= gormlogger.Error {
+		logger.LogErrorf("[SQL] %s %s", logger.ContextFields(ctx), fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace ...
+func (l *kitSQLLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.config.LogLevel <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	isError := err != nil && !(l.config.IgnoreRecordNotFoundError && errors.Is(err, gormlogger.ErrRecordNotFound))
+	isSlow := l.config.SlowThreshold > 0 && elapsed > l.config.SlowThreshold
+
+	switch {
+	case isError && l.config.LogLevel >= gormlogger.Error:
+		logger.LogErrorf("%s error=%q", l.format(ctx, elapsed, fc), err.Error())
+	case isSlow && l.config.LogLevel >= gormlogger.Warn:
+		logger.LogWarnf("%s slow_threshold=%v", l.format(ctx, elapsed, fc), l.config.SlowThreshold)
+	case l.config.LogLevel >= gormlogger.Info:
+		logger.LogNoticef("%s", l.format(ctx, elapsed, fc))
+	}
+}
+
+func (l *kitSQLLogger) format(ctx context.Context, elapsed time.Duration, fc func() (string, int64)) string {
+	sql, rows := fc()
+	info := statementInfoFrom(ctx)
+	if l.config.RedactParams {
+		// statements which kit callbacks did not run for have no SQL with placeholders
+		if info.sql != "" {
+			sql = info.sql
+		} else {
+			sql = redactSQL(sql)
+		}
+	}
+	return formatSQLLog(info.dbName, info.role, logger.ContextFields(ctx), elapsed, rows, sql)
+}
+
+// formatSQLLog omits database name, role and context fields when they are empty
+func formatSQLLog(dbName string, role string, fields string, elapsed time.Duration, rows int64, sql string) string {
+	rowsStr := "-"
+	if rows >= 0 {
+		rowsStr = strconv.FormatInt(rows, 10)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("[SQL] ")
+	if dbName != "" {
+		builder.WriteString("db=" + dbName + " ")
+	}
+	if role != "" {
+		builder.WriteString("role=" + role + " ")
+	}
+	if fields != "" {
+		builder.WriteString(fields + " ")
+	}
+	fmt.Fprintf(&builder, "duration=%.3fms rows=%s sql=%q", float64(elapsed.Nanoseconds())/1e6, rowsStr, sql)
+	return builder.String()
+}
+
+// redactSQL replaces quoted strings and numbers of SQL with ? for SQL whose parameters are already interpolated.
+// Identifiers quoted by backquotes are kept
+func redactSQL(sql string) string {
+	var builder strings.Builder
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'' || r == '"':
+			i = closingQuote(runes, i)
+			builder.WriteRune('?')
+		case r == '`':
+			end := closingQuote(runes, i)
+			if end >= len(runes) {
+				end = len(runes) - 1
+			}
+			builder.WriteString(string(runes[i : end+1]))
+			i = end
+		case unicode.IsDigit(r) && (i == 0 || !isSQLIdentifier(runes[i-1])):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			builder.WriteRune('?')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// closingQuote returns index of quote which closes quote at start. Doubled quotes and backslash escape quote
+func closingQuote(runes []rune, start int) int {
+	quote := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && quote != '`':
+			i++
+		case runes[i] == quote && i+1 < len(runes) && runes[i+1] == quote:
+			i++
+		case runes[i] == quote:
+			return i
+		}
+	}
+	return len(runes)
+}
+
+func isSQLIdentifier(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func roleOf(connType ConnType) string {
+	if connType == ConnTypeMaster {
+		return "master"
+	}
+	return "slave"
+}
+
+// sqlLoggerV1 prints SQL of Manager connections (jinzhu/gorm) through the kit logger
+type sqlLoggerV1 struct {
+	config SQLLoggerConfig
+	role   string
+}
+
+// Print ...
+func (l sqlLoggerV1) Print(values ...interface{}) {
+	if len(values) < 2 || l.config.LogLevel <= gormlogger.Silent {
+		return
+	}
+
+	switch {
+	case values[0] == "info" || len(values) == 2:
+		if l.config.LogLevel >= gormlogger.Info {
+			logger.LogNoticef("[SQL] role=%s %s", l.role, fmt.Sprint(values[1:]...))
+		}
+		return
+	case values[0] != "sql" || len(values) < 6:
+		if l.config.LogLevel >= gormlogger.Error {
+			logger.LogErrorf("[SQL] role=%s %s", l.role, fmt.Sprint(values[2:]...))
+		}
+		return
+	}
+
+	elapsed, _ := values[2].(time.Duration)
+	sql, _ := values[3].(string)
+	vars, _ := values[4].([]interface{})
+	rows, _ := values[5].(int64)
+	if !l.config.RedactParams {
+		sql = interpolateSQL(sql, vars)
+	}
+
+	// jinzhu/gorm passes neither database name nor context to logger
+	msg := formatSQLLog("", l.role, "", elapsed, rows, sql)
+	if l.config.SlowThreshold > 0 && elapsed > l.config.SlowThreshold {
+		logger.LogWarnf("%s slow_threshold=%v", msg, l.config.SlowThreshold)
+	} else {
+		logger.LogNoticef("%s", msg)
+	}
+}
+
+func interpolateSQL(sql string, vars []interface{}) string {
+	var builder strings.Builder
+	idx := 0
+	for _, r := range sql {
+		if r == '?' && idx < len(vars) {
+			builder.WriteString(formatSQLVar(vars[idx]))
+			idx++
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+func formatSQLVar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.Replace(v, "'", "''", -1) + "'"
+	case []byte:
+		return "'" + strings.Replace(string(v), "'", "''", -1) + "'"
+	case time.Time:
+		return "'" + v.Format("2006-01-02 15:04:05") + "'"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		return fmt.Sprint(v)
+	default:
+		return fmt.Sprintf("'%v'", v)
+	}
+}
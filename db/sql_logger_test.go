@@ -0,0 +1,156 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rakutentech/go-echo-kit/logger"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	gormlogger "gorm.io/gorm/logger"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+func openDryRunDB(t *testing.T, sqlLogger gormlogger.Interface) *gorm.DB {
+	conn, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true, SkipDefaultTransaction: true, Logger: sqlLogger})
+	assert.NoError(t, err)
+	callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+	assert.NoError(t, registerCallbacks(conn))
+	return conn
+}
+
+func captureLog(fn func()) string {
+	buf := new(bytes.Buffer)
+	logger.LogSetOutput(buf)
+	defer logger.LogSetOutput(os.Stderr)
+	fn()
+	return buf.String()
+}
+
+func TestSQLLogger(t *testing.T) {
+	tests := []struct {
+		haveConfig SQLLoggerConfig
+		wantLog    string
+	}{
+		{
+			SQLLoggerConfig{LogLevel: gormlogger.Info},
+			`[SQL] db=db2 role=slave request_id=rid duration=`,
+		},
+		{
+			SQLLoggerConfig{LogLevel: gormlogger.Info, RedactParams: true},
+			`sql="SELECT * FROM ` + "`users`" + ` WHERE name = ? AND ` + "`users`" + `.` + "`deleted_at`" + ` IS NULL"`,
+		},
+		{
+			SQLLoggerConfig{LogLevel: gormlogger.Info},
+			`sql="SELECT * FROM ` + "`users`" + ` WHERE name = \"secret\" AND ` + "`users`" + `.` + "`deleted_at`" + ` IS NULL"`,
+		},
+		{
+			SQLLoggerConfig{LogLevel: gormlogger.Warn, SlowThreshold: time.Nanosecond},
+			`slow_threshold=1ns`,
+		},
+		{
+			SQLLoggerConfig{LogLevel: gormlogger.Error},
+			``,
+		},
+	}
+
+	for _, test := range tests {
+		conn := openDryRunDB(t, NewSQLLogger(test.haveConfig))
+		gormDB = conn
+		databases = []Database{{Name: "db1", Default: true}, {Name: "db2"}}
+
+		ctx := logger.WithRequestID(context.Background(), "rid")
+		haveLog := captureLog(func() {
+			GetConnCtx(ctx, "db2", ConnTypeSlave).Where("name = ?", "secret").Find(&gormtests.User{})
+		})
+		if test.wantLog == "" {
+			assert.Empty(t, haveLog)
+		} else {
+			assert.Contains(t, haveLog, test.wantLog)
+		}
+	}
+	gormDB = nil
+	databases = nil
+}
+
+func TestSQLLoggerDefaultDatabase(t *testing.T) {
+	conn := openDryRunDB(t, NewSQLLogger(SQLLoggerConfig{LogLevel: gormlogger.Info}))
+	databases = []Database{{Name: "db1", Default: true}}
+	defer func() { databases = nil }()
+
+	haveLog := captureLog(func() {
+		conn.Create(&gormtests.User{Name: "test"})
+	})
+	assert.Contains(t, haveLog, "[SQL] db=db1 role=master duration=")
+	assert.Contains(t, haveLog, "rows=0 sql=\"INSERT INTO")
+}
+
+func TestSQLLoggerRedactWithoutCallbacks(t *testing.T) {
+	conn, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{
+		DryRun: true, SkipDefaultTransaction: true,
+		Logger: NewSQLLogger(SQLLoggerConfig{LogLevel: gormlogger.Info, RedactParams: true}),
+	})
+	assert.NoError(t, err)
+	callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+
+	haveLog := captureLog(func() {
+		conn.Where("name = ? AND age = ?", "secret", 20).Find(&gormtests.User{})
+	})
+	assert.Contains(t, haveLog, "[SQL] duration=")
+	assert.Contains(t, haveLog, `sql="SELECT * FROM `+"`users`"+` WHERE (name = ? AND age = ?) AND `+"`users`"+`.`+"`deleted_at`"+` IS NULL"`)
+	assert.NotContains(t, haveLog, "secret")
+}
+
+func TestRedactSQL(t *testing.T) {
+	tests := []struct {
+		haveSQL string
+		wantSQL string
+	}{
+		{"SELECT * FROM users WHERE id = 10", "SELECT * FROM users WHERE id = ?"},
+		{"SELECT * FROM users2 WHERE name = 'it''s' AND note = \"a\\\"b\"", "SELECT * FROM users2 WHERE name = ? AND note = ?"},
+		{"SELECT * FROM `t1` WHERE `col'1` = 1.5 LIMIT 3", "SELECT * FROM `t1` WHERE `col'1` = ? LIMIT ?"},
+		{"SELECT 'unclosed", "SELECT ?"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.wantSQL, redactSQL(test.haveSQL), test.haveSQL)
+	}
+}
+
+func TestSQLLoggerConfigFromConfig(t *testing.T) {
+	cfg := viper.New()
+	assert.NoError(t, cfg.MergeConfigMap(map[string]interface{}{
+		"slow_threshold": "200ms",
+		"level":          "Info",
+		"redact_params":  true,
+	}))
+
+	assert.Equal(t, SQLLoggerConfig{
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel:      gormlogger.Info,
+		RedactParams:  true,
+	}, SQLLoggerConfigFromConfig(cfg))
+	assert.Equal(t, DefaultSQLLoggerConfig, SQLLoggerConfigFromConfig(nil))
+}
+
+func TestSQLLoggerV1(t *testing.T) {
+	l := sqlLoggerV1{config: DefaultSQLLoggerConfig, role: "slave"}
+	haveLog := captureLog(func() {
+		l.Print("sql", "file.go:1", 2*time.Millisecond, "SELECT * FROM users WHERE id = ? AND name = ?", []interface{}{1, "it's"}, int64(1))
+	})
+	assert.Contains(t, haveLog, "[NOTICE]")
+	assert.Contains(t, haveLog, `[SQL] role=slave duration=2.000ms rows=1 sql="SELECT * FROM users WHERE id = 1 AND name = 'it''s'"`)
+
+	l.config.RedactParams = true
+	haveLog = captureLog(func() {
+		l.Print("sql", "file.go:1", 2*time.Second, "SELECT * FROM users WHERE id = ?", []interface{}{1}, int64(1))
+	})
+	assert.Contains(t, haveLog, "[WARNING]")
+	assert.Contains(t, haveLog, `[SQL] role=slave duration=2000.000ms rows=1 sql="SELECT * FROM users WHERE id = ?" slow_threshold=1s`)
+}
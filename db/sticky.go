@@ -0,0 +1,270 @@
+package db
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// StickyConfig defines the config for read-your-writes middleware
+type StickyConfig struct {
+	// Skipper defines a function to skip middleware
+	Skipper middleware.Skipper
+
+	// Window is how long reads are pinned to master after a write (default: 5s)
+	Window time.Duration
+
+	// CookieName keeps stickiness over requests of the same user session.
+	// Empty means stickiness lasts only within the request
+	CookieName string
+
+	// WaitGTID reads from slave after waiting for GTID of the last write instead of pinning to master (MySQL only).
+	// It works with Manager.SlaveConnCtx; a slave which does not catch up within GTIDTimeout falls back to master
+	WaitGTID bool
+
+	// GTIDTimeout is how long to wait for slave to apply GTID (default: 1s)
+	GTIDTimeout time.Duration
+
+	// SigningKey signs cookie by HMAC-SHA256, and cookies with invalid signature are ignored.
+	// GTID of cookie is used only when the cookie is signed. Expiry of cookie is always capped by Window
+	SigningKey []byte
+}
+
+// DefaultStickyConfig ...
+var DefaultStickyConfig = StickyConfig{
+	Skipper:     middleware.DefaultSkipper,
+	Window:      5 * time.Second,
+	CookieName:  "db_sticky",
+	GTIDTimeout: time.Second,
+}
+
+type stickyKey struct{}
+
+type stickyState struct {
+	mu        sync.Mutex
+	config    StickyConfig
+	expiresAt time.Time
+	gtid      string
+	wrote     bool
+}
+
+// StickyMiddleware pins reads to master after a write with DefaultStickyConfig
+func StickyMiddleware() echo.MiddlewareFunc {
+	return StickyMiddlewareWithConfig(DefaultStickyConfig)
+}
+
+// StickyMiddlewareWithConfig pins reads to master after a write
+// Writes are marked by MarkWrite (automatically for OpenDBConn connections used with request context)
+func StickyMiddlewareWithConfig(config StickyConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultStickyConfig.Skipper
+	}
+	if config.Window == 0 {
+		config.Window = DefaultStickyConfig.Window
+	}
+	if config.GTIDTimeout == 0 {
+		config.GTIDTimeout = DefaultStickyConfig.GTIDTimeout
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			state := &stickyState{config: config}
+			if config.CookieName != "" {
+				if cookie, err := c.Cookie(config.CookieName); err == nil {
+					state.expiresAt, state.gtid = decodeStickyCookie(cookie.Value, config.SigningKey)
+					// the cookie is controlled by client, so it cannot pin reads longer than Window
+					if maxExpiresAt := time.Now().Add(config.Window); state.expiresAt.After(maxExpiresAt) {
+						state.expiresAt = maxExpiresAt
+					}
+				}
+				c.Response().Before(func() {
+					if cookie := state.cookie(); cookie != nil {
+						c.SetCookie(cookie)
+					}
+				})
+			}
+
+			req := c.Request()
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), stickyKey{}, state)))
+			return next(c)
+		}
+	}
+}
+
+// MarkWrite marks request in ctx as recently wrote so that its reads go to master for a while
+func MarkWrite(ctx context.Context) {
+	MarkWriteGTID(ctx, "")
+}
+
+// MarkWriteGTID marks request in ctx as recently wrote with GTID set executed on master
+func MarkWriteGTID(ctx context.Context, gtid string) {
+	state := stickyStateFrom(ctx)
+	if state == nil {
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.wrote = true
+	state.expiresAt = time.Now().Add(state.config.Window)
+	if gtid != "" {
+		state.gtid = gtid
+	}
+}
+
+// IsSticky returns true when reads of request in ctx should go to master
+func IsSticky(ctx context.Context) bool {
+	state := stickyStateFrom(ctx)
+	if state == nil {
+		return false
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return time.Now().Before(state.expiresAt)
+}
+
+// ResolveConnType returns ConnTypeMaster for slave reads of request in ctx which recently wrote
+func ResolveConnType(ctx context.Context, connType ConnType) ConnType {
+	if connType == ConnTypeSlave && IsSticky(ctx) {
+		return ConnTypeMaster
+	}
+	return connType
+}
+
+// MarkWrite marks request in ctx as recently wrote, with GTID of master in WaitGTID mode
+func (m *Manager) MarkWrite(ctx context.Context) {
+	state := stickyStateFrom(ctx)
+	if state == nil {
+		return
+	}
+
+	gtid := ""
+	if state.config.WaitGTID {
+		var err error
+		if gtid, err = executedGTID(m.MasterConn()); err != nil {
+			gtid = ""
+		}
+	}
+	MarkWriteGTID(ctx, gtid)
+}
+
+// SlaveConnCtx will return slave connection, or master if request in ctx recently wrote
+func (m *Manager) SlaveConnCtx(ctx context.Context) *gorm.DB {
+	state := stickyStateFrom(ctx)
+	if state == nil || !IsSticky(ctx) {
+		return m.SlaveConn()
+	}
+
+	state.mu.Lock()
+	gtid, config := state.gtid, state.config
+	state.mu.Unlock()
+
+	if config.WaitGTID && gtid != "" && len(m.Slaves) > 0 {
+		slave := m.SlaveConn()
+		if waitForGTID(slave, gtid, config.GTIDTimeout) {
+			return slave
+		}
+	}
+	return m.MasterConn()
+}
+
+func stickyStateFrom(ctx context.Context) *stickyState {
+	if ctx == nil {
+		return nil
+	}
+	state, _ := ctx.Value(stickyKey{}).(*stickyState)
+	return state
+}
+
+func (s *stickyState) cookie() *http.Cookie {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.wrote {
+		return nil
+	}
+	return &http.Cookie{
+		Name:     s.config.CookieName,
+		Value:    encodeStickyCookie(s.expiresAt, s.gtid, s.config.SigningKey),
+		Path:     "/",
+		Expires:  s.expiresAt,
+		MaxAge:   int(time.Until(s.expiresAt).Seconds()) + 1,
+		HttpOnly: true,
+	}
+}
+
+// cookie value is "<expiry in unix millis>.<base64 GTID set>", and ".<base64 HMAC-SHA256>" when signed
+func encodeStickyCookie(expiresAt time.Time, gtid string, key []byte) string {
+	value := strconv.FormatInt(expiresAt.UnixNano()/int64(time.Millisecond), 10) +
+		"." + base64.RawURLEncoding.EncodeToString([]byte(gtid))
+	if len(key) != 0 {
+		value += "." + base64.RawURLEncoding.EncodeToString(signSticky(value, key))
+	}
+	return value
+}
+
+// decodeStickyCookie returns expiry and GTID of cookie. GTID is dropped when key is empty,
+// and the cookie is ignored when its signature is invalid
+func decodeStickyCookie(value string, key []byte) (time.Time, string) {
+	parts := strings.Split(value, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return time.Time{}, ""
+	}
+	signed := len(key) != 0
+	if signed {
+		if len(parts) != 3 {
+			return time.Time{}, ""
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil || !hmac.Equal(signature, signSticky(parts[0]+"."+parts[1], key)) {
+			return time.Time{}, ""
+		}
+	}
+
+	millis, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, ""
+	}
+	expiresAt := time.Unix(0, millis*int64(time.Millisecond))
+
+	gtid := ""
+	if signed {
+		if decoded, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+			gtid = string(decoded)
+		}
+	}
+	return expiresAt, gtid
+}
+
+func signSticky(payload string, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func executedGTID(conn *gorm.DB) (string, error) {
+	var gtid string
+	err := conn.Raw("SELECT @@GLOBAL.gtid_executed").Row().Scan(&gtid)
+	return gtid, err
+}
+
+// waitForGTID returns true when slave applied gtid within timeout
+func waitForGTID(conn *gorm.DB, gtid string, timeout time.Duration) bool {
+	var result int
+	err := conn.Raw("SELECT WAIT_FOR_EXECUTED_GTID_SET(?, ?)", gtid, timeout.Seconds()).Row().Scan(&result)
+	return err == nil && result == 0
+}
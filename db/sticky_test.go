@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func serveSticky(config StickyConfig, cookie *http.Cookie, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	_ = StickyMiddlewareWithConfig(config)(handler)(c)
+	return rec
+}
+
+func TestStickyMiddleware(t *testing.T) {
+	config := DefaultStickyConfig
+	config.SigningKey = []byte("secret")
+
+	var sticky []bool
+	rec := serveSticky(config, nil, func(c echo.Context) error {
+		ctx := c.Request().Context()
+		sticky = append(sticky, IsSticky(ctx))
+		assert.Equal(t, ConnTypeSlave, ResolveConnType(ctx, ConnTypeSlave))
+
+		MarkWriteGTID(ctx, "3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5")
+		sticky = append(sticky, IsSticky(ctx))
+		assert.Equal(t, ConnTypeMaster, ResolveConnType(ctx, ConnTypeSlave))
+		return c.NoContent(http.StatusOK)
+	})
+	assert.Equal(t, []bool{false, true}, sticky)
+
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, DefaultStickyConfig.CookieName, cookies[0].Name)
+
+	expiresAt, gtid := decodeStickyCookie(cookies[0].Value, config.SigningKey)
+	assert.WithinDuration(t, time.Now().Add(DefaultStickyConfig.Window), expiresAt, time.Second)
+	assert.Equal(t, "3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5", gtid)
+
+	// next request of the same session
+	rec = serveSticky(config, cookies[0], func(c echo.Context) error {
+		assert.True(t, IsSticky(c.Request().Context()))
+		return c.NoContent(http.StatusOK)
+	})
+	assert.Empty(t, rec.Result().Cookies())
+
+	// expired
+	expired := &http.Cookie{Name: config.CookieName, Value: encodeStickyCookie(time.Now().Add(-time.Second), "", config.SigningKey)}
+	serveSticky(config, expired, func(c echo.Context) error {
+		assert.False(t, IsSticky(c.Request().Context()))
+		return c.NoContent(http.StatusOK)
+	})
+}
+
+func TestStickyCookie(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	gtid := "3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5"
+	key := []byte("secret")
+
+	tests := []struct {
+		haveValue  string
+		haveKey    []byte
+		wantSticky bool
+		wantGTID   string
+	}{
+		// expiry of cookie is capped by Window
+		{encodeStickyCookie(future, gtid, key), key, true, gtid},
+		// GTID of unsigned cookie is dropped
+		{encodeStickyCookie(future, gtid, nil), nil, true, ""},
+		{"99999999999999.x", nil, true, ""},
+		// forged or unsigned cookies are ignored with key
+		{encodeStickyCookie(future, gtid, []byte("other")), key, false, ""},
+		{encodeStickyCookie(future, gtid, nil), key, false, ""},
+		{"99999999999999.x", key, false, ""},
+		{"invalid", nil, false, ""},
+	}
+
+	for _, tt := range tests {
+		config := DefaultStickyConfig
+		config.SigningKey = tt.haveKey
+		config.WaitGTID = true
+		serveSticky(config, &http.Cookie{Name: config.CookieName, Value: tt.haveValue}, func(c echo.Context) error {
+			state := stickyStateFrom(c.Request().Context())
+			assert.Equal(t, tt.wantSticky, IsSticky(c.Request().Context()), tt.haveValue)
+			assert.Equal(t, tt.wantGTID, state.gtid, tt.haveValue)
+			assert.False(t, state.expiresAt.After(time.Now().Add(config.Window)), tt.haveValue)
+			return nil
+		})
+	}
+}
+
+func TestStickyWithoutCookie(t *testing.T) {
+	config := DefaultStickyConfig
+	config.CookieName = ""
+	rec := serveSticky(config, nil, func(c echo.Context) error {
+		MarkWrite(c.Request().Context())
+		assert.True(t, IsSticky(c.Request().Context()))
+		return c.NoContent(http.StatusOK)
+	})
+	assert.Empty(t, rec.Result().Cookies())
+}
+
+func TestStickyWithoutMiddleware(t *testing.T) {
+	ctx := context.Background()
+	MarkWrite(ctx)
+	assert.False(t, IsSticky(ctx))
+	assert.Equal(t, ConnTypeSlave, ResolveConnType(ctx, ConnTypeSlave))
+}
+
+func TestStickyCallbacks(t *testing.T) {
+	conn, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	assert.NoError(t, err)
+	assert.NoError(t, registerCallbacks(conn))
+
+	serveSticky(DefaultStickyConfig, nil, func(c echo.Context) error {
+		ctx := c.Request().Context()
+		conn.WithContext(ctx).Find(&tests.User{})
+		assert.False(t, IsSticky(ctx))
+
+		conn.WithContext(ctx).Create(&tests.User{Name: "test"})
+		assert.True(t, IsSticky(ctx))
+		return nil
+	})
+
+	// Exec runs Raw callbacks
+	serveSticky(DefaultStickyConfig, nil, func(c echo.Context) error {
+		ctx := c.Request().Context()
+		conn.WithContext(ctx).Exec("SELECT 1")
+		assert.False(t, IsSticky(ctx))
+
+		conn.WithContext(ctx).Exec("UPDATE users SET name = ?", "test")
+		assert.True(t, IsSticky(ctx))
+		return nil
+	})
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const tracingSpanKey = "echokit:tracing_span"
+
+// TracingPlugin is gorm plugin which creates span per query as a child of statement context.
+// OpenDBConn does not register it, so register it to the connection after OpenDBConn
+// Usage dbConn.Use(db.NewTracingPlugin())
+type TracingPlugin struct {
+	// TracerProvider (default: global provider)
+	TracerProvider trace.TracerProvider
+}
+
+// NewTracingPlugin ...
+func NewTracingPlugin() *TracingPlugin {
+	return &TracingPlugin{}
+}
+
+// Name ...
+func (p *TracingPlugin) Name() string {
+	return "echokit:tracing"
+}
+
+// Initialize ...
+func (p *TracingPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	type register func(string, func(*gorm.DB)) error
+	processors := []struct {
+		operation string
+		before    register
+		after     register
+	}{
+		{"create", callbacks.Create().Before("*").Register, callbacks.Create().After("*").Register},
+		{"query", callbacks.Query().Before("*").Register, callbacks.Query().After("*").Register},
+		{"update", callbacks.Update().Before("*").Register, callbacks.Update().After("*").Register},
+		{"delete", callbacks.Delete().Before("*").Register, callbacks.Delete().After("*").Register},
+		{"row", callbacks.Row().Before("*").Register, callbacks.Row().After("*").Register},
+		{"raw", callbacks.Raw().Before("*").Register, callbacks.Raw().After("*").Register},
+	}
+
+	for _, processor := range processors {
+		if err := processor.before("echokit:tracing_start", p.startSpan(processor.operation)); err != nil {
+			return err
+		}
+		if err := processor.after("echokit:tracing_end", endSpan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *TracingPlugin) startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		provider := p.TracerProvider
+		if provider == nil {
+			provider = otel.GetTracerProvider()
+		}
+
+		// tracing starts before kit callbacks set statement info to context
+		info := newStatementInfo(db)
+		_, span := provider.Tracer("github.com/rakutentech/go-echo-kit").Start(db.Statement.Context, "db."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemKey.String(db.Dialector.Name()),
+				semconv.DBNameKey.String(info.dbName),
+				attribute.String("db.role", info.role),
+			),
+		)
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	// SQL with placeholders, no parameter values
+	sql := db.Statement.SQL.String()
+	span.SetAttributes(
+		semconv.DBStatementKey.String(sql),
+		semconv.DBOperationKey.String(sqlOperation(sql)),
+		semconv.DBSQLTableKey.String(db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestTracingPlugin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	conn := openDryRunDB(t, gormlogger.Discard)
+	assert.NoError(t, conn.Use(&TracingPlugin{TracerProvider: provider}))
+	gormDB = conn
+	databases = []Database{{Name: "db1", Default: true}, {Name: "db2"}}
+	defer func() {
+		gormDB = nil
+		databases = nil
+	}()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	GetConnCtx(ctx, "db2", ConnTypeSlave).Where("name = ?", "secret").Find(&tests.User{})
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "db.query", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	assert.Equal(t, "dummy", attrs["db.system"].AsString())
+	assert.Equal(t, "db2", attrs["db.name"].AsString())
+	assert.Equal(t, "slave", attrs["db.role"].AsString())
+	assert.Equal(t, "SELECT", attrs["db.operation"].AsString())
+	assert.Equal(t, "users", attrs["db.sql.table"].AsString())
+	assert.Equal(t, "SELECT * FROM `users` WHERE name = ? AND `users`.`deleted_at` IS NULL", attrs["db.statement"].AsString())
+}
@@ -0,0 +1,88 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CatalogJSON exports registered codes as JSON array for frontend and QA
+func CatalogJSON() ([]byte, error) {
+	return json.MarshalIndent(Codes(), "", "  ")
+}
+
+// CatalogMarkdown exports registered codes as Markdown table
+func CatalogMarkdown() string {
+	var b strings.Builder
+	b.WriteString("| Code | Status | Description | Message ID |\n")
+	b.WriteString("|:---|:---|:---|:---|\n")
+	for _, info := range Codes() {
+		fmt.Fprintf(&b, "| %s | %d | %s | %s |\n", info.Code, info.Status, escapeMarkdown(info.Description), info.MessageID)
+	}
+	return b.String()
+}
+
+func escapeMarkdown(value string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(value)
+}
+
+// CatalogOpenAPI exports registered codes as OpenAPI 3 components, which have ErrorCode and ErrorResponse schemas
+// and a response per code. Refer them like $ref: '#/components/responses/NotExistInDB_Error'
+func CatalogOpenAPI() ([]byte, error) {
+	codes := Codes()
+	enum := make([]string, 0, len(codes))
+	descriptions := make([]string, 0, len(codes))
+	responses := map[string]interface{}{}
+	for _, info := range codes {
+		enum = append(enum, string(info.Code))
+		descriptions = append(descriptions, fmt.Sprintf("- `%s` (%d): %s", info.Code, info.Status, info.Description))
+		responses[string(info.Code)] = map[string]interface{}{
+			"description": fmt.Sprintf("%d %s", info.Status, info.Description),
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema":  map[string]interface{}{"$ref": "#/components/schemas/ErrorResponse"},
+					"example": map[string]interface{}{"code": info.Code, "message": info.Description},
+				},
+			},
+		}
+	}
+
+	components := map[string]interface{}{
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"ErrorCode": map[string]interface{}{
+					"type":        "string",
+					"enum":        enum,
+					"description": strings.Join(descriptions, "\n"),
+				},
+				"FieldError": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"field", "tag", "message"},
+					"properties": map[string]interface{}{
+						"field":   map[string]interface{}{"type": "string"},
+						"tag":     map[string]interface{}{"type": "string"},
+						"param":   map[string]interface{}{"type": "string"},
+						"message": map[string]interface{}{"type": "string"},
+					},
+				},
+				"ErrorResponse": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"code", "message"},
+					"properties": map[string]interface{}{
+						"code":       map[string]interface{}{"$ref": "#/components/schemas/ErrorCode"},
+						"message":    map[string]interface{}{"type": "string"},
+						"request_id": map[string]interface{}{"type": "string"},
+						"details": map[string]interface{}{
+							"description": "Field errors of validation, details of the error, or items of multiple errors",
+						},
+						"retryable": map[string]interface{}{"type": "boolean"},
+						"total":     map[string]interface{}{"type": "integer", "description": "Number of multiple errors"},
+						"truncated": map[string]interface{}{"type": "integer", "description": "Number of multiple errors which are not in details"},
+					},
+				},
+			},
+			"responses": responses,
+		},
+	}
+	return json.MarshalIndent(components, "", "  ")
+}
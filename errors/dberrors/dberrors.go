@@ -0,0 +1,143 @@
+package dberrors
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+
+	"github.com/go-sql-driver/mysql"
+	gormv1 "github.com/jinzhu/gorm"
+	"gorm.io/gorm"
+)
+
+// Messages of translated errors, which do not leak values and schema in driver errors
+const (
+	MessageNotFound   = "record not found"
+	MessageDuplicate  = "record already exists"
+	MessageForeignKey = "record is referenced by or references other records"
+	MessageDeadlock   = "transaction deadlock, please retry"
+	MessageTimeout    = "database query timed out"
+	MessageCanceled   = "request canceled"
+	MessageNotOpened  = "database is not available"
+	MessageDB         = "database error"
+)
+
+// postgresError is implemented by errors of lib/pq and pgconn
+type postgresError interface {
+	SQLState() string
+}
+
+// mssqlError is implemented by errors of go-mssqldb
+type mssqlError interface {
+	SQLErrorNumber() int32
+}
+
+// unavailableError is implemented by db.ErrNotOpened and db.ErrUnknownDatabase
+type unavailableError interface {
+	Unavailable() bool
+}
+
+type dbErrorKind int
+
+const (
+	dbErrorUnknown dbErrorKind = iota
+	dbErrorDuplicate
+	dbErrorForeignKey
+	dbErrorDeadlock
+	dbErrorTimeout
+)
+
+// FromDB translates errors of gorm and database drivers into errors.Error with a fixed message, which keeps err as the cause
+//   - record not found of gorm v1 and v2: ErrCodeNotExistInDB
+//   - duplicate key (MySQL 1062, Postgres 23505, MSSQL 2601 and 2627): ErrCodeAlreadyExistsInDB
+//   - foreign key violation (MySQL 1451 and 1452, Postgres 23503, MSSQL 547): ErrCodeSQLIllegalState with 409
+//   - deadlock (MySQL 1213, Postgres 40P01 and 40001, MSSQL 1205): ErrCodeSQLIllegalState, retryable
+//   - timeout (context.DeadlineExceeded, MySQL 3024, Postgres 57014): ErrCodeSQLResult with 504, retryable
+//   - context.Canceled by client: ErrCodeRequestCanceled
+//   - db.ErrNotOpened and db.ErrUnknownDatabase: ErrCodeSQLIllegalState
+//   - other errors: ErrCodeSQLResult
+//
+// It returns nil for nil, and err itself when it already has errors.Error
+func FromDB(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e errors.Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) || gormv1.IsRecordNotFoundError(err) {
+		return errors.Wrap(errors.ErrCodeNotExistInDB, err, MessageNotFound)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return errors.Wrap(errors.ErrCodeRequestCanceled, err, MessageCanceled)
+	}
+	var unavailable unavailableError
+	if errors.As(err, &unavailable) && unavailable.Unavailable() {
+		return errors.Wrap(errors.ErrCodeSQLIllegalState, err, MessageNotOpened)
+	}
+
+	switch dbErrorKindOf(err) {
+	case dbErrorDuplicate:
+		return errors.Wrap(errors.ErrCodeAlreadyExistsInDB, err, MessageDuplicate)
+	case dbErrorForeignKey:
+		return errors.Wrap(errors.ErrCodeSQLIllegalState, err, MessageForeignKey).WithStatus(http.StatusConflict)
+	case dbErrorDeadlock:
+		return errors.Wrap(errors.ErrCodeSQLIllegalState, err, MessageDeadlock).WithRetryable(true)
+	case dbErrorTimeout:
+		return errors.Wrap(errors.ErrCodeSQLResult, err, MessageTimeout).WithStatus(http.StatusGatewayTimeout).WithRetryable(true)
+	}
+	return errors.Wrap(errors.ErrCodeSQLResult, err, MessageDB)
+}
+
+func dbErrorKindOf(err error) dbErrorKind {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return dbErrorTimeout
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1062:
+			return dbErrorDuplicate
+		case 1451, 1452:
+			return dbErrorForeignKey
+		case 1213:
+			return dbErrorDeadlock
+		case 3024:
+			return dbErrorTimeout
+		}
+		return dbErrorUnknown
+	}
+
+	var pgErr postgresError
+	if errors.As(err, &pgErr) {
+		switch pgErr.SQLState() {
+		case "23505":
+			return dbErrorDuplicate
+		case "23503":
+			return dbErrorForeignKey
+		case "40P01", "40001":
+			return dbErrorDeadlock
+		case "57014":
+			return dbErrorTimeout
+		}
+		return dbErrorUnknown
+	}
+
+	var mssqlErr mssqlError
+	if errors.As(err, &mssqlErr) {
+		switch mssqlErr.SQLErrorNumber() {
+		case 2601, 2627:
+			return dbErrorDuplicate
+		case 547:
+			return dbErrorForeignKey
+		case 1205:
+			return dbErrorDeadlock
+		}
+	}
+	return dbErrorUnknown
+}
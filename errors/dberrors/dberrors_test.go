@@ -0,0 +1,85 @@
+package dberrors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+
+	"github.com/go-sql-driver/mysql"
+	gormv1 "github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testPostgresError struct{ code string }
+
+func (e *testPostgresError) Error() string    { return "pq: " + e.code }
+func (e *testPostgresError) SQLState() string { return e.code }
+
+type testUnavailableError struct{}
+
+func (testUnavailableError) Error() string     { return "database connection is not opened" }
+func (testUnavailableError) Unavailable() bool { return true }
+
+type testMSSQLError struct{ number int32 }
+
+func (e testMSSQLError) Error() string         { return fmt.Sprintf("mssql: %d", e.number) }
+func (e testMSSQLError) SQLErrorNumber() int32 { return e.number }
+
+func TestFromDB(t *testing.T) {
+	tests := []struct {
+		haveErr       error
+		wantCode      errors.ErrorCode
+		wantMsg       string
+		wantStatus    int
+		wantRetryable bool
+	}{
+		{gorm.ErrRecordNotFound, errors.ErrCodeNotExistInDB, MessageNotFound, http.StatusNotFound, false},
+		{fmt.Errorf("find user: %w", gorm.ErrRecordNotFound), errors.ErrCodeNotExistInDB, MessageNotFound, http.StatusNotFound, false},
+		{gormv1.ErrRecordNotFound, errors.ErrCodeNotExistInDB, MessageNotFound, http.StatusNotFound, false},
+		{gormv1.Errors{gormv1.ErrRecordNotFound}, errors.ErrCodeNotExistInDB, MessageNotFound, http.StatusNotFound, false},
+		{&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}, errors.ErrCodeAlreadyExistsInDB, MessageDuplicate, http.StatusConflict, false},
+		{&mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"}, errors.ErrCodeSQLIllegalState, MessageForeignKey, http.StatusConflict, false},
+		{&mysql.MySQLError{Number: 1213, Message: "Deadlock found"}, errors.ErrCodeSQLIllegalState, MessageDeadlock, http.StatusInternalServerError, true},
+		{&mysql.MySQLError{Number: 1064, Message: "syntax error"}, errors.ErrCodeSQLResult, MessageDB, http.StatusInternalServerError, false},
+		{&testPostgresError{"23505"}, errors.ErrCodeAlreadyExistsInDB, MessageDuplicate, http.StatusConflict, false},
+		{&testPostgresError{"23503"}, errors.ErrCodeSQLIllegalState, MessageForeignKey, http.StatusConflict, false},
+		{&testPostgresError{"40P01"}, errors.ErrCodeSQLIllegalState, MessageDeadlock, http.StatusInternalServerError, true},
+		{testMSSQLError{2627}, errors.ErrCodeAlreadyExistsInDB, MessageDuplicate, http.StatusConflict, false},
+		{testMSSQLError{547}, errors.ErrCodeSQLIllegalState, MessageForeignKey, http.StatusConflict, false},
+		{testMSSQLError{1205}, errors.ErrCodeSQLIllegalState, MessageDeadlock, http.StatusInternalServerError, true},
+		{context.DeadlineExceeded, errors.ErrCodeSQLResult, MessageTimeout, http.StatusGatewayTimeout, true},
+		{fmt.Errorf("find user: %w", context.DeadlineExceeded), errors.ErrCodeSQLResult, MessageTimeout, http.StatusGatewayTimeout, true},
+		{&mysql.MySQLError{Number: 3024, Message: "maximum statement execution time exceeded"}, errors.ErrCodeSQLResult, MessageTimeout, http.StatusGatewayTimeout, true},
+		{&testPostgresError{"57014"}, errors.ErrCodeSQLResult, MessageTimeout, http.StatusGatewayTimeout, true},
+		{context.Canceled, errors.ErrCodeRequestCanceled, MessageCanceled, errors.StatusClientClosedRequest, false},
+		{fmt.Errorf("find user: %w", context.Canceled), errors.ErrCodeRequestCanceled, MessageCanceled, errors.StatusClientClosedRequest, false},
+		{fmt.Errorf("%w: db2", testUnavailableError{}), errors.ErrCodeSQLIllegalState, MessageNotOpened, http.StatusInternalServerError, false},
+		{stderrors.New("driver: bad connection"), errors.ErrCodeSQLResult, MessageDB, http.StatusInternalServerError, false},
+		{errors.NewErrorWithMsg(errors.ErrCodeValidationError, "invalid"), errors.ErrCodeValidationError, "invalid", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		err := FromDB(tt.haveErr)
+
+		var e errors.Error
+		if !assert.True(t, errors.As(err, &e), tt.haveErr.Error()) {
+			continue
+		}
+		assert.Equal(t, tt.wantCode, errors.CodeOf(err), tt.haveErr.Error())
+		assert.True(t, strings.HasPrefix(err.Error(), tt.wantMsg), tt.haveErr.Error())
+		assert.Equal(t, tt.wantStatus, e.Status(), tt.haveErr.Error())
+		assert.Equal(t, tt.wantRetryable, errors.IsRetryable(err), tt.haveErr.Error())
+		// gorm v1 Errors is a slice, which errors.Is can not compare
+		if _, ok := tt.haveErr.(gormv1.Errors); !ok {
+			assert.True(t, errors.Is(err, tt.haveErr), tt.haveErr.Error())
+		}
+	}
+
+	assert.Nil(t, FromDB(nil))
+}
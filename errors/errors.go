@@ -12,33 +12,205 @@ type ErrorCode string
 type Error struct {
 	error     error
 	errorCode ErrorCode
+	message   string
+
+	// meta is a pointer, so that Error is comparable like sentinel errors
+	meta *errorMeta
+}
+
+// errorMeta is stack trace and metadata of Error, which are copied on write by setters
+type errorMeta struct {
+	stack     stack
+	status    int
+	details   map[string]interface{}
+	messageID string
+	retryable bool
+}
+
+func newError(code ErrorCode, err error, msg string) Error {
+	e := Error{error: err, errorCode: code, message: msg}
+	if s := callers(code); s != nil {
+		e.meta = &errorMeta{stack: s}
+	}
+	return e
+}
+
+// metadata returns the metadata, or zero value
+func (err Error) metadata() errorMeta {
+	if err.meta == nil {
+		return errorMeta{}
+	}
+	return *err.meta
+}
+
+// withMetadata returns a copy of the Error with metadata changed by set
+func (err Error) withMetadata(set func(meta *errorMeta)) Error {
+	meta := err.metadata()
+	set(&meta)
+	err.meta = &meta
+	return err
 }
 
 // NewError - creates an Error instance with built-in error
 func NewError(code ErrorCode, err error) Error {
-	return Error{error: err, errorCode: code}
+	return newError(code, err, "")
 }
 
 // NewErrorWithMsg - creates an Error instance with custom message
 func NewErrorWithMsg(code ErrorCode, msg string) Error {
-	return NewError(code, errors.New(msg))
+	return newError(code, nil, msg)
 }
 
 // NewErrorWithMsgf - creates an Error instance with formatted message
 func NewErrorWithMsgf(code ErrorCode, format string, values ...interface{}) Error {
-	msg := fmt.Sprintf(format, values...)
-	return NewError(code, errors.New(msg))
+	return newError(code, nil, fmt.Sprintf(format, values...))
+}
+
+// Wrap - creates an Error instance with message which keeps err as the cause
+// Usage errors.Wrap(errors.ErrCodeNotExistInDB, err, "user not found")
+func Wrap(code ErrorCode, err error, msg string) Error {
+	return newError(code, err, msg)
+}
+
+// Wrapf - creates an Error instance with formatted message which keeps err as the cause
+func Wrapf(code ErrorCode, err error, format string, values ...interface{}) Error {
+	return newError(code, err, fmt.Sprintf(format, values...))
 }
 
 // ErrorCode - get the ErrorCode of an Error instance
-// Usage err.(errors.Error).ErrorCode()
+// Usage errors.CodeOf(err), which also works for wrapped errors
 func (err Error) ErrorCode() string {
 	return string(err.errorCode)
 }
 
 // Implementation of built-in error interface
 func (err Error) Error() string {
-	return err.error.Error()
+	if err.message == "" {
+		if err.error == nil {
+			return string(err.errorCode)
+		}
+		return err.error.Error()
+	}
+	if err.error == nil {
+		return err.message
+	}
+	return err.message + ": " + err.error.Error()
+}
+
+// WithStatus - returns a copy of the Error with HTTP status, which overrides status of the code
+func (err Error) WithStatus(status int) Error {
+	return err.withMetadata(func(meta *errorMeta) { meta.status = status })
+}
+
+// WithDetail - returns a copy of the Error with machine-readable detail like resource ID
+// Usage errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user not found").WithDetail("user_id", id)
+func (err Error) WithDetail(key string, value interface{}) Error {
+	return err.withMetadata(func(meta *errorMeta) {
+		details := make(map[string]interface{}, len(meta.details)+1)
+		for k, v := range meta.details {
+			details[k] = v
+		}
+		details[key] = value
+		meta.details = details
+	})
+}
+
+// WithMessageID - returns a copy of the Error with i18n message ID of user-facing message (default: the code)
+func (err Error) WithMessageID(messageID string) Error {
+	return err.withMetadata(func(meta *errorMeta) { meta.messageID = messageID })
+}
+
+// WithRetryable - returns a copy of the Error which tells clients whether to retry
+func (err Error) WithRetryable(retryable bool) Error {
+	return err.withMetadata(func(meta *errorMeta) { meta.retryable = retryable })
+}
+
+// Status - get the HTTP status set by WithStatus, or the status of the code
+func (err Error) Status() int {
+	if status := err.metadata().status; status != 0 {
+		return status
+	}
+	return StatusOf(err.errorCode)
+}
+
+// Details - get the details set by WithDetail
+func (err Error) Details() map[string]interface{} {
+	return err.metadata().details
+}
+
+// MessageID - get the message ID set by WithMessageID, or registered message ID of the code, or the code
+func (err Error) MessageID() string {
+	if messageID := err.metadata().messageID; messageID != "" {
+		return messageID
+	}
+	if info, ok := Lookup(err.errorCode); ok && info.MessageID != "" {
+		return info.MessageID
+	}
+	return string(err.errorCode)
+}
+
+// Retryable - get the retryable flag
+func (err Error) Retryable() bool {
+	return err.metadata().retryable
+}
+
+// Unwrap - get the cause for errors.Is and errors.As
+func (err Error) Unwrap() error {
+	return err.error
+}
+
+// Is - matches Error and ErrorCode with the same code
+// Usage errors.Is(err, errors.ErrCodeNotExistInDB)
+func (err Error) Is(target error) bool {
+	switch t := target.(type) {
+	case Error:
+		return t.errorCode == err.errorCode
+	case *Error:
+		return t != nil && t.errorCode == err.errorCode
+	case ErrorCode:
+		return t == err.errorCode
+	}
+	return false
+}
+
+// Implementation of built-in error interface, so that ErrorCode can be the target of errors.Is
+func (code ErrorCode) Error() string {
+	return string(code)
+}
+
+// CodeOf - get the ErrorCode of the first Error or Multi in the chain, or empty code
+func CodeOf(err error) ErrorCode {
+	var multi *Multi
+	if As(err, &multi) {
+		return multi.code
+	}
+	var e Error
+	if errors.As(err, &e) {
+		return e.errorCode
+	}
+	return ""
+}
+
+// IsRetryable - returns true when an Error in the chain is retryable
+func IsRetryable(err error) bool {
+	var e Error
+	for As(err, &e) {
+		if e.Retryable() {
+			return true
+		}
+		err = e.error
+	}
+	return false
+}
+
+// Is - alias of built-in errors.Is
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As - alias of built-in errors.As
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
 }
 
 // Error Codes
@@ -58,4 +230,5 @@ const (
 	ErrCodeFileAlreadyExists       ErrorCode = "FileAlreadyExists_Error"
 	ErrCodeAlreadyExistsInDB       ErrorCode = "AlreadyExistsInDB_Error"
 	ErrCodeNotExistInDB            ErrorCode = "NotExistInDB_Error"
+	ErrCodeRequestCanceled         ErrorCode = "RequestCanceled_Error"
 )
@@ -2,7 +2,9 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -10,12 +12,13 @@ func TestNewErrorWithMsg(t *testing.T) {
 	var testErrCode ErrorCode = "hoge"
 	var testMsg = "fuga fuga"
 
-	var want = Error{errors.New("fuga fuga"), "hoge"}
+	var want = Error{error: errors.New("fuga fuga"), errorCode: "hoge"}
 	var got = NewErrorWithMsg(testErrCode, testMsg)
 
-	if !reflect.DeepEqual(want, got) {
+	if want.Error() != got.Error() || want.ErrorCode() != got.ErrorCode() {
 		t.Errorf("want: %v but got: %v", want, got)
 	}
+	assertComparable(t, got)
 }
 
 // NewErrorWithMsgf - creates an Error instance with formatted message
@@ -24,10 +27,168 @@ func TestNewErrorWithMsgf(t *testing.T) {
 	var testFormat = "%s, %s, %s"
 	var testValue1, testValue2, testValue3 = "fuga1", "fuga2", "fuga3"
 
-	var want = Error{errors.New("fuga1, fuga2, fuga3"), "hoge"}
+	var want = Error{error: errors.New("fuga1, fuga2, fuga3"), errorCode: "hoge"}
 	var got = NewErrorWithMsgf(testErrCode, testFormat, testValue1, testValue2, testValue3)
 
-	if !reflect.DeepEqual(want, got) {
+	if want.Error() != got.Error() || want.ErrorCode() != got.ErrorCode() {
 		t.Errorf("want: %v but got: %v", want, got)
 	}
+	assertComparable(t, got)
+}
+
+// assertComparable checks Error works as sentinel error, which is compared by ==
+func assertComparable(t *testing.T, sentinel error) {
+	err := sentinel
+	if err != sentinel {
+		t.Errorf("want: %v is equal to itself", sentinel)
+	}
+	if err == error(NewErrorWithMsg(ErrCodeUnexpectedError, "other")) {
+		t.Errorf("want: %v is not equal to other error", sentinel)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	errNotFound := errors.New("record not found")
+	err := Wrap(ErrCodeNotExistInDB, errNotFound, "user 1")
+
+	if got := err.Error(); got != "user 1: record not found" {
+		t.Errorf("want: %v but got: %v", "user 1: record not found", got)
+	}
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("want: errors.Is cause but got: false")
+	}
+	if got := Wrap(ErrCodeNotExistInDB, nil, "user 1").Error(); got != "user 1" {
+		t.Errorf("want: %v but got: %v", "user 1", got)
+	}
+}
+
+func TestIsAndCodeOf(t *testing.T) {
+	errNotFound := errors.New("record not found")
+	wrapped := fmt.Errorf("get user: %w", Wrap(ErrCodeNotExistInDB, errNotFound, "user 1"))
+
+	tests := []struct {
+		haveErr    error
+		haveTarget error
+		wantIs     bool
+	}{
+		{wrapped, ErrCodeNotExistInDB, true},
+		{wrapped, NewErrorWithMsg(ErrCodeNotExistInDB, "other message"), true},
+		{wrapped, errNotFound, true},
+		{wrapped, ErrCodeAlreadyExistsInDB, false},
+		{errNotFound, ErrCodeNotExistInDB, false},
+	}
+
+	for _, tt := range tests {
+		if got := Is(tt.haveErr, tt.haveTarget); got != tt.wantIs {
+			t.Errorf("%v is %v want: %v but got: %v", tt.haveErr, tt.haveTarget, tt.wantIs, got)
+		}
+	}
+
+	if got := CodeOf(wrapped); got != ErrCodeNotExistInDB {
+		t.Errorf("want: %v but got: %v", ErrCodeNotExistInDB, got)
+	}
+	if got := CodeOf(errNotFound); got != "" {
+		t.Errorf("want: empty code but got: %v", got)
+	}
+
+	var e Error
+	if !As(wrapped, &e) || e.ErrorCode() != string(ErrCodeNotExistInDB) {
+		t.Errorf("want: As finds Error but got: %v", e)
+	}
+}
+
+func newTestError() Error {
+	return NewErrorWithMsg(ErrCodeSQLIllegalState, "connection refused")
+}
+
+func TestStack(t *testing.T) {
+	defer SetStackCapture(StackCapture())
+	SetStackCapture(true)
+
+	err := Wrap(ErrCodeUnexpectedError, newTestError(), "get user")
+	if stack := err.Stack(); !strings.HasPrefix(stack, "\tgithub.com/rakutentech/go-echo-kit/errors.TestStack\n") {
+		t.Errorf("want: stack from TestStack but got: %v", stack)
+	}
+	if stack := StackOf(fmt.Errorf("%w", err)); stack != err.Stack() {
+		t.Errorf("want: %v but got: %v", err.Stack(), stack)
+	}
+
+	formatted := fmt.Sprintf("%+v", err)
+	for _, want := range []string{
+		"Unexpected_Error: get user: connection refused\n\tgithub.com/rakutentech/go-echo-kit/errors.TestStack\n",
+		"\ncaused by: SQL_IllegalState: connection refused\n\tgithub.com/rakutentech/go-echo-kit/errors.newTestError\n",
+	} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("want: %v in %v", want, formatted)
+		}
+	}
+	for _, verb := range []string{"%v", "%s"} {
+		if got := fmt.Sprintf(verb, err); got != "get user: connection refused" {
+			t.Errorf("%s want: %v but got: %v", verb, "get user: connection refused", got)
+		}
+	}
+	// other verbs format the message as errors without Format do
+	for _, verb := range []string{"%q", "%x", "%X", "% x", "%.3s", "%-30s|", "%10.3v"} {
+		want := fmt.Sprintf(verb, errors.New("get user: connection refused"))
+		if got := fmt.Sprintf(verb, err); got != want {
+			t.Errorf("%s want: %v but got: %v", verb, want, got)
+		}
+	}
+	if got := fmt.Sprintf("%d", err); got != "%!d(string=get user: connection refused)" {
+		t.Errorf("%%d want: bad verb but got: %v", got)
+	}
+
+	// client errors do not capture stack traces
+	if stack := NewErrorWithMsg(ErrCodeNotExistInDB, "user not found").Stack(); stack != "" {
+		t.Errorf("want: empty stack of 4xx code but got: %v", stack)
+	}
+
+	SetStackSkip(1)
+	defer SetStackSkip(0)
+	if stack := newTestError().Stack(); !strings.HasPrefix(stack, "\tgithub.com/rakutentech/go-echo-kit/errors.TestStack\n") {
+		t.Errorf("want: stack from TestStack skipping newTestError but got: %v", stack)
+	}
+
+	SetStackCapture(false)
+	if stack := newTestError().Stack(); stack != "" {
+		t.Errorf("want: empty stack but got: %v", stack)
+	}
+	if got := fmt.Sprintf("%+v", NewErrorWithMsg(ErrCodeSQLIllegalState, "connection refused")); got != "SQL_IllegalState: connection refused" {
+		t.Errorf("want: %v but got: %v", "SQL_IllegalState: connection refused", got)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	base := NewErrorWithMsg(ErrCodeNotExistInDB, "user not found")
+	err := base.WithStatus(410).WithDetail("user_id", 1).WithDetail("group", "a").WithMessageID("user.gone").WithRetryable(true)
+	assertComparable(t, err)
+
+	if got := base.Status(); got != 404 {
+		t.Errorf("want: %v but got: %v", 404, got)
+	}
+	if got := base.MessageID(); got != string(ErrCodeNotExistInDB) {
+		t.Errorf("want: %v but got: %v", ErrCodeNotExistInDB, got)
+	}
+	if base.Details() != nil || base.Retryable() {
+		t.Errorf("want: base is not changed but got: %v %v", base.Details(), base.Retryable())
+	}
+
+	if got := err.Status(); got != 410 {
+		t.Errorf("want: %v but got: %v", 410, got)
+	}
+	if want, got := map[string]interface{}{"user_id": 1, "group": "a"}, err.Details(); !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v but got: %v", want, got)
+	}
+	if got := err.MessageID(); got != "user.gone" {
+		t.Errorf("want: %v but got: %v", "user.gone", got)
+	}
+	if !err.Retryable() || !IsRetryable(fmt.Errorf("%w", Wrap(ErrCodeUnexpectedError, err, "get user"))) {
+		t.Errorf("want: retryable but got: false")
+	}
+	if IsRetryable(base) || IsRetryable(errors.New("other")) {
+		t.Errorf("want: not retryable but got: true")
+	}
+	if got := err.ErrorCode(); got != string(ErrCodeNotExistInDB) {
+		t.Errorf("want: %v but got: %v", ErrCodeNotExistInDB, got)
+	}
 }
@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"strings"
+)
+
+// FieldError is a validation failure of request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// FieldErrors is the cause of ErrCodeValidationError returned by CustomContext.BindAndValidate
+type FieldErrors []FieldError
+
+// Implementation of built-in error interface
+func (fieldErrors FieldErrors) Error() string {
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		messages = append(messages, fieldError.Field+": "+fieldError.Message)
+	}
+	return strings.Join(messages, ", ")
+}
+
+// NewValidationError - creates an Error instance with ErrCodeValidationError and field errors
+func NewValidationError(fieldErrors FieldErrors) Error {
+	return newError(ErrCodeValidationError, fieldErrors, "")
+}
+
+// FieldErrorsOf returns field errors in the chain of validation error
+func FieldErrorsOf(err error) (FieldErrors, bool) {
+	var fieldErrors FieldErrors
+	ok := As(err, &fieldErrors)
+	return fieldErrors, ok
+}
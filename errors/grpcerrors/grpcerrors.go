@@ -0,0 +1,271 @@
+package grpcerrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is domain of errdetails.ErrorInfo, whose reason is the kit code
+var Domain = "github.com/rakutentech/go-echo-kit"
+
+// HideInternalMessage makes ToStatus use errors.PublicMessage like httperrors.HTTPErrorHandler (default: APP_ENV is prod)
+var HideInternalMessage = strings.ToLower(os.Getenv("APP_ENV")) == "prod"
+
+// metadata keys of ErrorInfo which are not details
+const (
+	metadataMessageID = "message_id"
+	metadataRetryable = "retryable"
+)
+
+var (
+	codesMutex sync.RWMutex
+	grpcCodes  = map[errors.ErrorCode]codes.Code{
+		errors.ErrCodeParameterIllegalState:   codes.InvalidArgument,
+		errors.ErrCodeSQLResult:               codes.Internal,
+		errors.ErrCodeSQLIllegalState:         codes.FailedPrecondition,
+		errors.ErrCodeEmailIllegalState:       codes.Internal,
+		errors.ErrCodeMonitoringAbnormalState: codes.Unavailable,
+		errors.ErrCodeMissingParamsError:      codes.InvalidArgument,
+		errors.ErrCodeValidationError:         codes.InvalidArgument,
+		errors.ErrCodeDuplicateParamsError:    codes.InvalidArgument,
+		errors.ErrCodeUnexpectedError:         codes.Internal,
+		errors.ErrCodeHTTPRequestIllegalState: codes.Unavailable,
+		errors.ErrCodeFileAlreadyExists:       codes.AlreadyExists,
+		errors.ErrCodeAlreadyExistsInDB:       codes.AlreadyExists,
+		errors.ErrCodeNotExistInDB:            codes.NotFound,
+		errors.ErrCodeRequestCanceled:         codes.Canceled,
+	}
+
+	// kit codes of gRPC status without ErrorInfo
+	kitCodes = map[codes.Code]errors.ErrorCode{
+		codes.InvalidArgument:    errors.ErrCodeParameterIllegalState,
+		codes.NotFound:           errors.ErrCodeNotExistInDB,
+		codes.AlreadyExists:      errors.ErrCodeAlreadyExistsInDB,
+		codes.FailedPrecondition: errors.ErrCodeSQLIllegalState,
+		codes.Unavailable:        errors.ErrCodeHTTPRequestIllegalState,
+	}
+
+	// gRPC codes of HTTP status for codes which are not in grpcCodes
+	httpCodes = map[int]codes.Code{
+		http.StatusBadRequest:          codes.InvalidArgument,
+		http.StatusUnauthorized:        codes.Unauthenticated,
+		http.StatusForbidden:           codes.PermissionDenied,
+		http.StatusNotFound:            codes.NotFound,
+		http.StatusConflict:            codes.AlreadyExists,
+		http.StatusPreconditionFailed:  codes.FailedPrecondition,
+		http.StatusTooManyRequests:     codes.ResourceExhausted,
+		http.StatusNotImplemented:      codes.Unimplemented,
+		http.StatusServiceUnavailable:  codes.Unavailable,
+		http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+		http.StatusInternalServerError: codes.Internal,
+	}
+)
+
+// RegisterCode maps kit code to gRPC code, or overrides gRPC code of built-in code.
+// Codes which are not registered are mapped by their HTTP status
+func RegisterCode(code errors.ErrorCode, grpcCode codes.Code) {
+	codesMutex.Lock()
+	defer codesMutex.Unlock()
+	grpcCodes[code] = grpcCode
+}
+
+// CodeOf returns gRPC code of kit code
+func CodeOf(code errors.ErrorCode) codes.Code {
+	codesMutex.RLock()
+	grpcCode, ok := grpcCodes[code]
+	codesMutex.RUnlock()
+	if ok {
+		return grpcCode
+	}
+	if grpcCode, ok := httpCodes[errors.StatusOf(code)]; ok {
+		return grpcCode
+	}
+	return codes.Unknown
+}
+
+// ToStatus converts error into gRPC status with errdetails.ErrorInfo whose reason is the kit code.
+// Field errors of validation are carried as errdetails.BadRequest. It returns nil for nil.
+// When HideInternalMessage is true, the message is errors.PublicMessage, which hides messages of 5xx errors and causes
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, context.Canceled.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, context.DeadlineExceeded.Error())
+	}
+
+	code := errors.CodeOf(err)
+	if code == "" {
+		code = errors.ErrCodeUnexpectedError
+	}
+	grpcCode := CodeOf(code)
+	httpStatus := errors.StatusOf(code)
+	info := &errdetails.ErrorInfo{Reason: string(code), Domain: Domain, Metadata: map[string]string{}}
+
+	var e errors.Error
+	if errors.As(err, &e) && errors.ErrorCode(e.ErrorCode()) == code {
+		httpStatus = e.Status()
+		if e.Status() != errors.StatusOf(code) {
+			// WithStatus overrides the code mapping like REST responses
+			if c, ok := httpCodes[e.Status()]; ok {
+				grpcCode = c
+			}
+		}
+		for key, value := range e.Details() {
+			info.Metadata[key] = fmt.Sprint(value)
+		}
+		if e.MessageID() != string(code) {
+			info.Metadata[metadataMessageID] = e.MessageID()
+		}
+	}
+	if errors.IsRetryable(err) {
+		info.Metadata[metadataRetryable] = "true"
+	}
+
+	msg := err.Error()
+	if HideInternalMessage {
+		msg = errors.PublicMessage(err, httpStatus)
+	}
+	st := status.New(grpcCode, msg)
+	withDetails, detailsErr := st.WithDetails(info)
+	if fieldErrors, ok := errors.FieldErrorsOf(err); ok && detailsErr == nil {
+		withDetails, detailsErr = withDetails.WithDetails(badRequestOf(fieldErrors))
+	}
+	if detailsErr != nil {
+		return st
+	}
+	return withDetails
+}
+
+// ToError converts error into gRPC status error for handlers of gRPC server
+func ToError(err error) error {
+	return ToStatus(err).Err()
+}
+
+// FromStatus converts gRPC status into errors.Error, restoring code, details, message ID and field errors of
+// ErrorInfo and BadRequest. Status without ErrorInfo is converted by its gRPC code. It returns nil for OK
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	var info *errdetails.ErrorInfo
+	var fieldErrors errors.FieldErrors
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if info == nil && d.GetDomain() == Domain {
+				info = d
+			}
+		case *errdetails.BadRequest:
+			fieldErrors = fieldErrorsOf(d)
+		}
+	}
+
+	code, ok := kitCodes[st.Code()]
+	if !ok {
+		code = errors.ErrCodeUnexpectedError
+	}
+	if info != nil {
+		code = errors.ErrorCode(info.GetReason())
+	}
+
+	var e errors.Error
+	if fieldErrors != nil {
+		e = errors.NewError(code, fieldErrors)
+	} else {
+		e = errors.NewErrorWithMsg(code, st.Message())
+	}
+	if info == nil {
+		return e
+	}
+
+	keys := make([]string, 0, len(info.GetMetadata()))
+	for key := range info.GetMetadata() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := info.GetMetadata()[key]
+		switch key {
+		case metadataMessageID:
+			e = e.WithMessageID(value)
+		case metadataRetryable:
+			retryable, _ := strconv.ParseBool(value)
+			e = e.WithRetryable(retryable)
+		default:
+			e = e.WithDetail(key, value)
+		}
+	}
+	return e
+}
+
+// FromError converts gRPC status error into errors.Error, and returns other errors as they are
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return FromStatus(st)
+}
+
+// UnaryServerInterceptor converts errors of handlers into gRPC status
+// Usage grpc.NewServer(grpc.UnaryInterceptor(grpcerrors.UnaryServerInterceptor()))
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToError(err)
+		}
+		return resp, nil
+	}
+}
+
+// UnaryClientInterceptor converts gRPC status of responses into errors.Error
+// Usage grpc.Dial(target, grpc.WithUnaryInterceptor(grpcerrors.UnaryClientInterceptor()))
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+func badRequestOf(fieldErrors errors.FieldErrors) *errdetails.BadRequest {
+	badRequest := &errdetails.BadRequest{}
+	for _, fieldError := range fieldErrors {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldError.Field,
+			Description: fieldError.Message,
+		})
+	}
+	return badRequest
+}
+
+func fieldErrorsOf(badRequest *errdetails.BadRequest) errors.FieldErrors {
+	fieldErrors := errors.FieldErrors{}
+	for _, violation := range badRequest.GetFieldViolations() {
+		fieldErrors = append(fieldErrors, errors.FieldError{Field: violation.GetField(), Message: violation.GetDescription()})
+	}
+	return fieldErrors
+}
@@ -0,0 +1,154 @@
+package grpcerrors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer returns errors of services by name
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	errs map[string]error
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if err := s.errs[req.GetService()]; err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func dialBufconn(t *testing.T, errs map[string]error, opts ...grpc.DialOption) (grpc_health_v1.HealthClient, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor()))
+	grpc_health_v1.RegisterHealthServer(server, &healthServer{errs: errs})
+	go func() { _ = server.Serve(listener) }()
+
+	opts = append(opts, grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	conn, err := grpc.DialContext(context.Background(), "bufnet", opts...)
+	assert.NoError(t, err)
+	return grpc_health_v1.NewHealthClient(conn), func() {
+		_ = conn.Close()
+		server.Stop()
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	const quota errors.ErrorCode = "GRPCQuota_Error"
+
+	tests := []struct {
+		haveErr  error
+		wantCode codes.Code
+		wantMsg  string
+		wantInfo string
+	}{
+		{errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user 1 not found"), codes.NotFound, "user 1 not found", "NotExistInDB_Error"},
+		{errors.NewErrorWithMsg(errors.ErrCodeAlreadyExistsInDB, "duplicate"), codes.AlreadyExists, "duplicate", "AlreadyExistsInDB_Error"},
+		{errors.NewErrorWithMsg(errors.ErrCodeValidationError, "invalid"), codes.InvalidArgument, "invalid", "Validation_Error"},
+		{errors.NewErrorWithMsg(quota, "quota exceeded").WithStatus(http.StatusTooManyRequests), codes.ResourceExhausted, "quota exceeded", "GRPCQuota_Error"},
+		{errors.NewErrorWithMsg(errors.ErrCodeSQLResult, "gone").WithStatus(http.StatusServiceUnavailable), codes.Unavailable, "gone", "SQL_Result"},
+		{context.DeadlineExceeded, codes.DeadlineExceeded, "context deadline exceeded", ""},
+		{fmt.Errorf("find user: %w", context.Canceled), codes.Canceled, "context canceled", ""},
+		{status.Error(codes.PermissionDenied, "denied"), codes.PermissionDenied, "denied", ""},
+		{assert.AnError, codes.Internal, assert.AnError.Error(), "Unexpected_Error"},
+	}
+
+	for _, tt := range tests {
+		st := ToStatus(tt.haveErr)
+		assert.Equal(t, tt.wantCode, st.Code(), tt.haveErr.Error())
+		assert.Equal(t, tt.wantMsg, st.Message(), tt.haveErr.Error())
+
+		reason := ""
+		for _, detail := range st.Details() {
+			if info, ok := detail.(*errdetails.ErrorInfo); ok {
+				reason = info.GetReason()
+				assert.Equal(t, Domain, info.GetDomain())
+			}
+		}
+		assert.Equal(t, tt.wantInfo, reason, tt.haveErr.Error())
+	}
+	assert.Nil(t, ToStatus(nil))
+}
+
+func TestToStatusHideInternalMessage(t *testing.T) {
+	HideInternalMessage = true
+	defer func() { HideInternalMessage = false }()
+
+	driverErr := stderrors.New("Error 1062: Duplicate entry 'alice@example.com' for key 'email'")
+	tests := []struct {
+		haveErr error
+		wantMsg string
+	}{
+		{errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user 1 not found"), "user 1 not found"},
+		{errors.NewError(errors.ErrCodeAlreadyExistsInDB, driverErr), "Conflict"},
+		{errors.Wrap(errors.ErrCodeAlreadyExistsInDB, driverErr, "user already exists"), "user already exists"},
+		{errors.NewErrorWithMsg(errors.ErrCodeSQLResult, "dial tcp 10.0.0.1:3306"), "Internal Server Error"},
+		{assert.AnError, "Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantMsg, ToStatus(tt.haveErr).Message(), tt.haveErr.Error())
+	}
+}
+
+func TestBufconn(t *testing.T) {
+	validationErr := errors.NewValidationError(errors.FieldErrors{{Field: "service", Tag: "required", Message: "service is required"}})
+	notFound := errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user 1 not found").
+		WithDetail("user_id", 1).WithMessageID("user.not_found").WithRetryable(true)
+
+	errs := map[string]error{
+		"not_found":  notFound,
+		"validation": validationErr,
+		"plain":      status.Error(codes.NotFound, "plain not found"),
+	}
+
+	// raw gRPC status of server
+	client, closeRaw := dialBufconn(t, errs)
+	defer closeRaw()
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "not_found"})
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "user 1 not found", st.Message())
+
+	// kit errors restored by client interceptor
+	client, closeKit := dialBufconn(t, errs, grpc.WithUnaryInterceptor(UnaryClientInterceptor()))
+	defer closeKit()
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "not_found"})
+	var e errors.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, errors.ErrCodeNotExistInDB, errors.CodeOf(err))
+	assert.Equal(t, "user 1 not found", err.Error())
+	assert.Equal(t, http.StatusNotFound, e.Status())
+	assert.Equal(t, map[string]interface{}{"user_id": "1"}, e.Details())
+	assert.Equal(t, "user.not_found", e.MessageID())
+	assert.True(t, e.Retryable())
+
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "validation"})
+	assert.Equal(t, errors.ErrCodeValidationError, errors.CodeOf(err))
+	fieldErrors, ok := errors.FieldErrorsOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, errors.FieldErrors{{Field: "service", Message: "service is required"}}, fieldErrors)
+
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "plain"})
+	assert.Equal(t, errors.ErrCodeNotExistInDB, errors.CodeOf(err))
+	assert.Equal(t, "plain not found", err.Error())
+
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "ok"})
+	assert.NoError(t, err)
+}
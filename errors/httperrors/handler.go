@@ -0,0 +1,190 @@
+package httperrors
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+	"github.com/rakutentech/go-echo-kit/logger"
+	"github.com/rakutentech/go-echo-kit/messages"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// HTTPErrorResponse is JSON body rendered by HTTPErrorHandler
+type HTTPErrorResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	RequestID string      `json:"request_id,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+	Retryable bool        `json:"retryable,omitempty"`
+
+	// Total and Truncated are the number of errors of Multi, and the number of them dropped from details by the cap
+	Total     int `json:"total,omitempty"`
+	Truncated int `json:"truncated,omitempty"`
+}
+
+// HTTPErrorHandlerConfig defines the config for HTTPErrorHandler
+type HTTPErrorHandlerConfig struct {
+	// HideInternalMessage replaces messages of 5xx errors and messages of causes like driver errors with status text,
+	// in responses and their Multi items, and drops details of 5xx errors (default: APP_ENV is prod)
+	HideInternalMessage bool
+}
+
+// HTTPErrorHandler renders errors as HTTPErrorResponse. It hides internal messages when APP_ENV is prod
+// Usage e.HTTPErrorHandler = httperrors.HTTPErrorHandler()
+func HTTPErrorHandler() echo.HTTPErrorHandler {
+	return HTTPErrorHandlerWithConfig(HTTPErrorHandlerConfig{
+		HideInternalMessage: strings.ToLower(os.Getenv("APP_ENV")) == "prod",
+	})
+}
+
+// HTTPErrorHandlerWithConfig renders errors.Error with status of its code and localized message whose ID is the code,
+// echo.HTTPError with its status, and other errors as ErrCodeUnexpectedError. 5xx errors are logged
+func HTTPErrorHandlerWithConfig(config HTTPErrorHandlerConfig) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		status, response := newHTTPErrorResponse(err, c, config.HideInternalMessage)
+		if config.HideInternalMessage && status >= http.StatusInternalServerError {
+			response.Details = nil
+		}
+		if status >= http.StatusInternalServerError {
+			req := c.Request()
+			stack := errors.StackOf(err)
+			if stack != "" {
+				stack = "\n" + stack
+			}
+			fields := logger.ContextFields(req.Context())
+			if fields != "" {
+				fields = " " + fields
+			}
+			logger.LogErrorf("[HTTP] %s %s status=%d code=%s%s: %v%s", req.Method, req.URL.Path, status, response.Code, fields, err, stack)
+		}
+
+		var writeErr error
+		if c.Request().Method == http.MethodHead {
+			writeErr = c.NoContent(status)
+		} else {
+			writeErr = c.JSON(status, response)
+		}
+		if writeErr != nil {
+			logger.LogErrorf("[HTTP] failed to write error response: %v", writeErr)
+		}
+	}
+}
+
+func newHTTPErrorResponse(err error, c echo.Context, hide bool) (int, HTTPErrorResponse) {
+	response := HTTPErrorResponse{RequestID: requestIDOf(c)}
+
+	// Multi first, because As of Multi finds Error of its items
+	var multi *errors.Multi
+	if errors.As(err, &multi) {
+		response.Code = multi.ErrorCode()
+		response.Message = localize(c, response.Code, map[string]interface{}{"Count": multi.Len()})
+		if response.Message == "" {
+			response.Message = fmt.Sprintf("%d errors", multi.Len())
+		}
+		response.Details = multi
+		response.Total = multi.Len()
+		response.Truncated = multi.Truncated()
+		if hide {
+			response.Details = errors.PublicItems(multi.Items())
+		}
+		return errors.StatusOf(errors.ErrorCode(multi.ErrorCode())), response
+	}
+
+	var e errors.Error
+	if errors.As(err, &e) {
+		response.Code = e.ErrorCode()
+		response.Message = localize(c, e.MessageID(), e.Details())
+		if response.Message == "" {
+			response.Message = messageOf(err, e.Status(), hide)
+		}
+		response.Details = detailsOf(err, e)
+		response.Retryable = errors.IsRetryable(err)
+		return e.Status(), response
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		response.Code = strings.Replace(http.StatusText(he.Code), " ", "", -1)
+		response.Message = fmt.Sprint(he.Message)
+		if hide && he.Code >= http.StatusInternalServerError {
+			response.Message = http.StatusText(he.Code)
+		}
+		return he.Code, response
+	}
+
+	response.Code = string(errors.ErrCodeUnexpectedError)
+	response.Message = messageOf(err, http.StatusInternalServerError, hide)
+	return http.StatusInternalServerError, response
+}
+
+// HTTPStatus returns status of response which HTTPErrorHandler writes for err
+func HTTPStatus(err error) int {
+	var multi *errors.Multi
+	if errors.As(err, &multi) {
+		return errors.StatusOf(errors.ErrorCode(multi.ErrorCode()))
+	}
+	var e errors.Error
+	if errors.As(err, &e) {
+		return e.Status()
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// messageOf returns err.Error(), or the public message when hide is true
+func messageOf(err error, status int, hide bool) string {
+	if !hide {
+		return err.Error()
+	}
+	return errors.PublicMessage(err, status)
+}
+
+// detailsOf returns field errors, details of Error, or details with "fields" when both exist
+func detailsOf(err error, e errors.Error) interface{} {
+	fieldErrors, hasFieldErrors := errors.FieldErrorsOf(err)
+	errDetails := e.Details()
+	if len(errDetails) == 0 {
+		if hasFieldErrors {
+			return fieldErrors
+		}
+		return nil
+	}
+	if !hasFieldErrors {
+		return errDetails
+	}
+	details := make(map[string]interface{}, len(errDetails)+1)
+	for k, v := range errDetails {
+		details[k] = v
+	}
+	details["fields"] = fieldErrors
+	return details
+}
+
+func localize(c echo.Context, id string, templateData map[string]interface{}) string {
+	if messages.LocalizerFrom(c.Request().Context()) == nil {
+		return ""
+	}
+	m := messages.NewMessage(c)
+	msg, _ := m.Lookup(id, templateData)
+	return msg
+}
+
+func requestIDOf(c echo.Context) string {
+	if requestID := logger.RequestID(c.Request().Context()); requestID != "" {
+		return requestID
+	}
+	if requestID := c.Response().Header().Get(echo.HeaderXRequestID); requestID != "" {
+		return requestID
+	}
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
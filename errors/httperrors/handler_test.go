@@ -0,0 +1,176 @@
+package httperrors
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+	"github.com/rakutentech/go-echo-kit/logger"
+	"github.com/rakutentech/go-echo-kit/messages"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+// errCodeTeapot is registered once for the test binary
+var errCodeTeapot = errors.MustRegister("Teapot_Error", http.StatusTeapot, "I'm a teapot", "")
+
+func serveError(handler echo.HTTPErrorHandler, err error, localizer *i18n.Localizer) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.HTTPErrorHandler = handler
+	e.GET("/users/:id", func(c echo.Context) error {
+		if localizer != nil {
+			c.SetRequest(c.Request().WithContext(messages.WithLocalizer(c.Request().Context(), localizer)))
+		}
+		return err
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		haveErr    error
+		wantStatus int
+		wantBody   string
+	}{
+		{errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user 1 not found"), http.StatusNotFound,
+			`{"code":"NotExistInDB_Error","message":"user 1 not found","request_id":"req-1"}`},
+		{fmt.Errorf("get user: %w", errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user 1 not found")), http.StatusNotFound,
+			`{"code":"NotExistInDB_Error","message":"get user: user 1 not found","request_id":"req-1"}`},
+		{errors.NewErrorWithMsg(errors.ErrCodeAlreadyExistsInDB, "duplicate"), http.StatusConflict,
+			`{"code":"AlreadyExistsInDB_Error","message":"duplicate","request_id":"req-1"}`},
+		{errors.NewValidationError(errors.FieldErrors{{Field: "name", Tag: "required", Message: "name is required"}}), http.StatusBadRequest,
+			`{"code":"Validation_Error","message":"name: name is required","request_id":"req-1","details":[{"field":"name","tag":"required","message":"name is required"}]}`},
+		{errors.NewErrorWithMsg(errors.ErrCodeSQLIllegalState, "deadlock").WithStatus(http.StatusServiceUnavailable).WithRetryable(true), http.StatusServiceUnavailable,
+			`{"code":"SQL_IllegalState","message":"deadlock","request_id":"req-1","retryable":true}`},
+		{errors.NewValidationError(errors.FieldErrors{{Field: "name", Tag: "required", Message: "name is required"}}).WithDetail("row", 3), http.StatusBadRequest,
+			`{"code":"Validation_Error","message":"name: name is required","request_id":"req-1","details":{"row":3,"fields":[{"field":"name","tag":"required","message":"name is required"}]}}`},
+		{errors.NewErrorWithMsg(errCodeTeapot, "short and stout"), http.StatusTeapot,
+			`{"code":"Teapot_Error","message":"short and stout","request_id":"req-1"}`},
+		{errors.NewErrorWithMsg("Unknown_Error", "unknown"), http.StatusInternalServerError,
+			`{"code":"Unknown_Error","message":"unknown","request_id":"req-1"}`},
+		{echo.NewHTTPError(http.StatusMethodNotAllowed, "no"), http.StatusMethodNotAllowed,
+			`{"code":"MethodNotAllowed","message":"no","request_id":"req-1"}`},
+		{stderrors.New("connection refused"), http.StatusInternalServerError,
+			`{"code":"Unexpected_Error","message":"connection refused","request_id":"req-1"}`},
+	}
+
+	buf := &bytes.Buffer{}
+	logger.LogSetOutput(buf)
+	defer logger.LogSetOutput(os.Stderr)
+
+	for _, tt := range tests {
+		rec := serveError(HTTPErrorHandlerWithConfig(HTTPErrorHandlerConfig{}), tt.haveErr, nil)
+		assert.Equal(t, tt.wantStatus, rec.Code, tt.haveErr.Error())
+		assert.JSONEq(t, tt.wantBody, rec.Body.String(), tt.haveErr.Error())
+	}
+	assert.Contains(t, buf.String(), "[HTTP] GET /users/1 status=500 code=Unexpected_Error: connection refused")
+	if errors.StackCapture() {
+		assert.Contains(t, buf.String(), "code=Unknown_Error: unknown\n\tgithub.com/rakutentech/go-echo-kit/errors/httperrors.TestHTTPErrorHandler\n")
+	} else {
+		assert.Contains(t, buf.String(), "code=Unknown_Error: unknown\n")
+	}
+	assert.NotContains(t, buf.String(), "status=404")
+}
+
+func TestHTTPErrorHandlerLocalize(t *testing.T) {
+	bundle := i18n.NewBundle(language.English)
+	bundle.MustAddMessages(language.English,
+		&i18n.Message{ID: "NotExistInDB_Error", Other: "The resource does not exist"},
+		&i18n.Message{ID: "user.not_found", Other: "User {{.user_id}} does not exist"},
+	)
+	localizer := i18n.NewLocalizer(bundle, "en")
+
+	rec := serveError(HTTPErrorHandlerWithConfig(HTTPErrorHandlerConfig{}), errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "record not found"), localizer)
+	assert.JSONEq(t, `{"code":"NotExistInDB_Error","message":"The resource does not exist","request_id":"req-1"}`, rec.Body.String())
+
+	err := errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "record not found").WithMessageID("user.not_found").WithDetail("user_id", 1)
+	rec = serveError(HTTPErrorHandlerWithConfig(HTTPErrorHandlerConfig{}), err, localizer)
+	assert.JSONEq(t, `{"code":"NotExistInDB_Error","message":"User 1 does not exist","request_id":"req-1","details":{"user_id":1}}`, rec.Body.String())
+}
+
+func TestHTTPErrorHandlerHideInternalMessage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger.LogSetOutput(buf)
+	defer logger.LogSetOutput(os.Stderr)
+
+	handler := HTTPErrorHandlerWithConfig(HTTPErrorHandlerConfig{HideInternalMessage: true})
+
+	rec := serveError(handler, errors.NewErrorWithMsg(errors.ErrCodeSQLIllegalState, "dial tcp 10.0.0.1:3306: connection refused").WithDetail("host", "10.0.0.1"), nil)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"code":"SQL_IllegalState","message":"Internal Server Error","request_id":"req-1"}`, rec.Body.String())
+	assert.Contains(t, buf.String(), "dial tcp 10.0.0.1:3306")
+
+	driverErr := stderrors.New("Error 1062: Duplicate entry 'alice@example.com' for key 'email'")
+	tests := []struct {
+		haveErr  error
+		wantBody string
+	}{
+		{errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user 1 not found"),
+			`{"code":"NotExistInDB_Error","message":"user 1 not found","request_id":"req-1"}`},
+		{fmt.Errorf("get user: %w", errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user 1 not found")),
+			`{"code":"NotExistInDB_Error","message":"user 1 not found","request_id":"req-1"}`},
+		{errors.NewError(errors.ErrCodeAlreadyExistsInDB, driverErr),
+			`{"code":"AlreadyExistsInDB_Error","message":"Conflict","request_id":"req-1"}`},
+		{errors.Wrap(errors.ErrCodeAlreadyExistsInDB, driverErr, "user already exists"),
+			`{"code":"AlreadyExistsInDB_Error","message":"user already exists","request_id":"req-1"}`},
+		{errors.NewError(errors.ErrCodeNotExistInDB, errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user 1 not found")),
+			`{"code":"NotExistInDB_Error","message":"user 1 not found","request_id":"req-1"}`},
+		{errors.NewValidationError(errors.FieldErrors{{Field: "name", Tag: "required", Message: "name is required"}}),
+			`{"code":"Validation_Error","message":"name: name is required","request_id":"req-1","details":[{"field":"name","tag":"required","message":"name is required"}]}`},
+		{echo.NewHTTPError(http.StatusServiceUnavailable, "redis 10.0.0.2:6379 is down"),
+			`{"code":"ServiceUnavailable","message":"Service Unavailable","request_id":"req-1"}`},
+		{echo.NewHTTPError(http.StatusMethodNotAllowed, "no"),
+			`{"code":"MethodNotAllowed","message":"no","request_id":"req-1"}`},
+		{stderrors.New("connection refused"),
+			`{"code":"Unexpected_Error","message":"Internal Server Error","request_id":"req-1"}`},
+	}
+	for _, tt := range tests {
+		rec = serveError(handler, tt.haveErr, nil)
+		assert.JSONEq(t, tt.wantBody, rec.Body.String(), tt.haveErr.Error())
+		assert.NotContains(t, rec.Body.String(), "alice@example.com")
+	}
+
+	multi := errors.NewMulti(errors.ErrCodeValidationError, 0)
+	multi.Add(0, "", errors.NewError(errors.ErrCodeAlreadyExistsInDB, driverErr))
+	multi.Add(1, "", stderrors.New("dial tcp 10.0.0.1:3306: connection refused"))
+	multi.Add(2, "", errors.NewErrorWithMsg(errors.ErrCodeNotExistInDB, "user 3 not found"))
+	rec = serveError(handler, multi, nil)
+	assert.JSONEq(t, `{"code":"Validation_Error","message":"3 errors","request_id":"req-1","total":3,"details":[
+		{"index":0,"code":"AlreadyExistsInDB_Error","message":"Conflict"},
+		{"index":1,"code":"Unexpected_Error","message":"Internal Server Error"},
+		{"index":2,"code":"NotExistInDB_Error","message":"user 3 not found"}]}`, rec.Body.String())
+}
+
+func TestMultiHTTPErrorHandler(t *testing.T) {
+	multi := errors.NewMulti(errors.ErrCodeValidationError, 0)
+	multi.Add(0, "alice", errors.NewErrorWithMsg(errors.ErrCodeAlreadyExistsInDB, "alice exists"))
+	multi.Add(2, "bob", errors.NewErrorWithMsg(errors.ErrCodeAlreadyExistsInDB, "bob exists"))
+
+	rec := serveError(HTTPErrorHandlerWithConfig(HTTPErrorHandlerConfig{}), multi.Err(), nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"code":"Validation_Error","message":"2 errors","request_id":"req-1","total":2,"details":[
+		{"index":0,"key":"alice","code":"AlreadyExistsInDB_Error","message":"alice exists"},
+		{"index":2,"key":"bob","code":"AlreadyExistsInDB_Error","message":"bob exists"}
+	]}`, rec.Body.String())
+
+	truncated := errors.NewMulti(errors.ErrCodeValidationError, 1)
+	truncated.Add(0, "alice", errors.NewError(errors.ErrCodeAlreadyExistsInDB, stderrors.New("Duplicate entry 'alice@example.com'")))
+	truncated.Add(1, "bob", errors.NewErrorWithMsg(errors.ErrCodeAlreadyExistsInDB, "bob exists"))
+	rec = serveError(HTTPErrorHandlerWithConfig(HTTPErrorHandlerConfig{HideInternalMessage: true}), truncated.Err(), nil)
+	assert.JSONEq(t, `{"code":"Validation_Error","message":"2 errors","request_id":"req-1","total":2,"truncated":1,"details":[
+		{"index":0,"key":"alice","code":"AlreadyExistsInDB_Error","message":"Conflict"}
+	]}`, rec.Body.String())
+}
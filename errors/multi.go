@@ -0,0 +1,145 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultMultiLimit is the size cap of Multi
+const DefaultMultiLimit = 100
+
+// MultiItem is an error of an item in batch operations
+type MultiItem struct {
+	Index   int         `json:"index"`
+	Key     string      `json:"key,omitempty"`
+	Code    ErrorCode   `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+
+	err error
+}
+
+// Err - get the error of the item
+func (item MultiItem) Err() error {
+	return item.err
+}
+
+// Multi - collects errors of items in batch operations. It is not safe for concurrent use
+type Multi struct {
+	code      ErrorCode
+	limit     int
+	items     []MultiItem
+	truncated int
+}
+
+// NewMulti - creates a Multi whose code is used for HTTP status, with size cap (0 for DefaultMultiLimit)
+// Usage multi := errors.NewMulti(errors.ErrCodeValidationError, 0); multi.Add(i, row.ID, err); return multi.Err()
+func NewMulti(code ErrorCode, limit int) *Multi {
+	if limit <= 0 {
+		limit = DefaultMultiLimit
+	}
+	return &Multi{code: code, limit: limit}
+}
+
+// Add - adds error of item with index and optional key. It ignores nil, and returns false when the cap is reached
+func (m *Multi) Add(index int, key string, err error) bool {
+	if err == nil {
+		return true
+	}
+	if len(m.items) >= m.limit {
+		m.truncated++
+		return false
+	}
+
+	item := MultiItem{Index: index, Key: key, Code: CodeOf(err), Message: err.Error(), err: err}
+	if item.Code == "" {
+		item.Code = ErrCodeUnexpectedError
+	}
+	if fieldErrors, ok := FieldErrorsOf(err); ok {
+		item.Details = fieldErrors
+	} else {
+		var e Error
+		if As(err, &e) && len(e.Details()) > 0 {
+			item.Details = e.Details()
+		}
+	}
+	m.items = append(m.items, item)
+	return true
+}
+
+// Len - get the number of errors, including truncated ones
+func (m *Multi) Len() int {
+	return len(m.items) + m.truncated
+}
+
+// Items - get errors of items up to the cap
+func (m *Multi) Items() []MultiItem {
+	return m.items
+}
+
+// Truncated - get the number of errors dropped by the cap
+func (m *Multi) Truncated() int {
+	return m.truncated
+}
+
+// ErrorCode - get the code of the Multi
+func (m *Multi) ErrorCode() string {
+	return string(m.code)
+}
+
+// Err - returns the Multi, or nil when it has no errors
+func (m *Multi) Err() error {
+	if m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// Implementation of built-in error interface
+func (m *Multi) Error() string {
+	messages := make([]string, 0, len(m.items))
+	for _, item := range m.items {
+		if item.Key == "" {
+			messages = append(messages, fmt.Sprintf("[%d] %s", item.Index, item.Message))
+		} else {
+			messages = append(messages, fmt.Sprintf("[%d:%s] %s", item.Index, item.Key, item.Message))
+		}
+	}
+	msg := fmt.Sprintf("%d errors: %s", m.Len(), strings.Join(messages, "; "))
+	if m.truncated > 0 {
+		msg += fmt.Sprintf("; and %d more", m.truncated)
+	}
+	return msg
+}
+
+// Is - returns true when the target is the code of the Multi, or any item matches the target
+func (m *Multi) Is(target error) bool {
+	if code, ok := target.(ErrorCode); ok && code == m.code {
+		return true
+	}
+	for _, item := range m.items {
+		if Is(item.err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As - finds the first item which matches the target
+func (m *Multi) As(target interface{}) bool {
+	for _, item := range m.items {
+		if As(item.err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON - renders items as JSON array
+func (m *Multi) MarshalJSON() ([]byte, error) {
+	if m.items == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(m.items)
+}
@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMulti(t *testing.T) {
+	errNotFound := errors.New("record not found")
+	multi := NewMulti(ErrCodeValidationError, 3)
+	assert.Nil(t, multi.Err())
+
+	assert.True(t, multi.Add(0, "alice", nil))
+	assert.True(t, multi.Add(1, "bob", NewValidationError(FieldErrors{{Field: "email", Tag: "required", Message: "email is required"}})))
+	assert.True(t, multi.Add(2, "", Wrap(ErrCodeNotExistInDB, errNotFound, "group 9").WithDetail("group_id", 9)))
+	assert.True(t, multi.Add(3, "carol", errors.New("unexpected")))
+	assert.False(t, multi.Add(4, "dave", errors.New("dropped")))
+	assert.False(t, multi.Add(5, "eve", errors.New("dropped")))
+
+	err := multi.Err()
+	assert.Equal(t, 5, multi.Len())
+	assert.Equal(t, 2, multi.Truncated())
+	assert.Equal(t, "5 errors: [1:bob] email: email is required; [2] group 9: record not found; [3:carol] unexpected; and 2 more", err.Error())
+	assert.Equal(t, ErrCodeValidationError, CodeOf(err))
+
+	assert.True(t, Is(err, errNotFound))
+	assert.True(t, Is(err, ErrCodeNotExistInDB))
+	assert.True(t, Is(err, ErrCodeValidationError))
+	assert.False(t, Is(err, ErrCodeAlreadyExistsInDB))
+
+	var e Error
+	assert.True(t, As(err, &e))
+	assert.Equal(t, string(ErrCodeValidationError), e.ErrorCode())
+	var fieldErrors FieldErrors
+	assert.True(t, As(err, &fieldErrors))
+
+	body, jsonErr := json.Marshal(err)
+	assert.NoError(t, jsonErr)
+	assert.JSONEq(t, `[
+		{"index":1,"key":"bob","code":"Validation_Error","message":"email: email is required","details":[{"field":"email","tag":"required","message":"email is required"}]},
+		{"index":2,"code":"NotExistInDB_Error","message":"group 9: record not found","details":{"group_id":9}},
+		{"index":3,"key":"carol","code":"Unexpected_Error","message":"unexpected"}
+	]`, string(body))
+}
@@ -0,0 +1,41 @@
+package errors
+
+import "net/http"
+
+// PublicMessage returns the message which is safe for clients: status text for 5xx errors, and for others
+// the message of the first Error in the chain which has its own message, or messages of field errors.
+// Messages of other causes like driver errors are replaced with status text
+func PublicMessage(err error, status int) string {
+	if status >= http.StatusInternalServerError {
+		return http.StatusText(status)
+	}
+	var e Error
+	for As(err, &e) {
+		if e.message != "" {
+			return e.message
+		}
+		if fieldErrors, ok := e.error.(FieldErrors); ok {
+			return fieldErrors.Error()
+		}
+		err = e.error
+	}
+	return http.StatusText(status)
+}
+
+// PublicItems returns a copy of items whose messages are PublicMessage
+func PublicItems(items []MultiItem) []MultiItem {
+	public := make([]MultiItem, len(items))
+	for i, item := range items {
+		public[i] = item
+		public[i].Message = PublicMessage(item.err, statusOfItem(item))
+	}
+	return public
+}
+
+func statusOfItem(item MultiItem) int {
+	var e Error
+	if As(item.err, &e) {
+		return e.Status()
+	}
+	return StatusOf(item.Code)
+}
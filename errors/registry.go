@@ -0,0 +1,110 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// CodeInfo is a registered error code
+type CodeInfo struct {
+	Code        ErrorCode `json:"code"`
+	Status      int       `json:"status"`
+	Description string    `json:"description"`
+	MessageID   string    `json:"message_id,omitempty"`
+}
+
+// StatusClientClosedRequest is the status of ErrCodeRequestCanceled, which clients do not receive
+const StatusClientClosedRequest = 499
+
+var (
+	registryMutex sync.RWMutex
+	registry      = map[ErrorCode]CodeInfo{}
+)
+
+func init() {
+	for _, info := range []CodeInfo{
+		{ErrCodeParameterIllegalState, http.StatusBadRequest, "Parameter of request is invalid", ""},
+		{ErrCodeSQLResult, http.StatusInternalServerError, "Database returned an error", ""},
+		{ErrCodeSQLIllegalState, http.StatusInternalServerError, "Database is not available for the operation", ""},
+		{ErrCodeEmailIllegalState, http.StatusInternalServerError, "Failed to send email", ""},
+		{ErrCodeMonitoringAbnormalState, http.StatusServiceUnavailable, "Monitoring detected abnormal state", ""},
+		{ErrCodeMissingParamsError, http.StatusBadRequest, "Required parameter is missing", ""},
+		{ErrCodeValidationError, http.StatusBadRequest, "Request failed validation", ""},
+		{ErrCodeDuplicateParamsError, http.StatusBadRequest, "Parameter is duplicated", ""},
+		{ErrCodeUnexpectedError, http.StatusInternalServerError, "Unexpected error", ""},
+		{ErrCodeHTTPRequestIllegalState, http.StatusBadGateway, "Request to upstream service failed", ""},
+		{ErrCodeFileAlreadyExists, http.StatusConflict, "File already exists", ""},
+		{ErrCodeAlreadyExistsInDB, http.StatusConflict, "Resource already exists", ""},
+		{ErrCodeNotExistInDB, http.StatusNotFound, "Resource does not exist", ""},
+		{ErrCodeRequestCanceled, StatusClientClosedRequest, "Client canceled the request", ""},
+	} {
+		registry[info.Code] = info
+	}
+}
+
+// Register adds error code of service with HTTP status, description for documents and i18n message ID
+// (empty for the code). It returns an error for registered codes
+func Register(code ErrorCode, status int, description, messageID string) error {
+	if code == "" {
+		return fmt.Errorf("error code is empty")
+	}
+	if http.StatusText(status) == "" {
+		return fmt.Errorf("invalid HTTP status of %s: %d", code, status)
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if _, ok := registry[code]; ok {
+		return fmt.Errorf("error code is already registered: %s", code)
+	}
+	registry[code] = CodeInfo{Code: code, Status: status, Description: description, MessageID: messageID}
+	return nil
+}
+
+// MustRegister is Register which panics with the error, for package level registration
+// Usage var ErrCodeQuotaExceeded = errors.MustRegister("QuotaExceeded_Error", 429, "Quota is exceeded", "")
+func MustRegister(code ErrorCode, status int, description, messageID string) ErrorCode {
+	if err := Register(code, status, description, messageID); err != nil {
+		panic(err)
+	}
+	return code
+}
+
+// RegisterStatus is Register of code with HTTP status only. It returns an error for registered codes,
+// so built-in codes keep their status
+func RegisterStatus(code ErrorCode, status int) error {
+	return Register(code, status, "", "")
+}
+
+// Lookup returns registered code
+func Lookup(code ErrorCode) (CodeInfo, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	info, ok := registry[code]
+	return info, ok
+}
+
+// StatusOf returns HTTP status of code, or 500 for unknown codes
+func StatusOf(code ErrorCode) int {
+	if info, ok := Lookup(code); ok && info.Status != 0 {
+		return info.Status
+	}
+	return http.StatusInternalServerError
+}
+
+// Codes returns registered codes sorted by code
+func Codes() []CodeInfo {
+	registryMutex.RLock()
+	codes := make([]CodeInfo, 0, len(registry))
+	for _, info := range registry {
+		codes = append(codes, info)
+	}
+	registryMutex.RUnlock()
+
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].Code < codes[j].Code
+	})
+	return codes
+}
@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// unregister removes code added by Register in tests
+func unregister(code ErrorCode) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	delete(registry, code)
+}
+
+func TestRegister(t *testing.T) {
+	const quota ErrorCode = "QuotaExceeded_Error"
+	defer unregister(quota)
+
+	assert.NoError(t, Register(quota, http.StatusTooManyRequests, "Quota | limit is exceeded", "quota.exceeded"))
+	assert.Error(t, Register(quota, http.StatusTooManyRequests, "again", ""))
+	assert.Error(t, Register(ErrCodeNotExistInDB, http.StatusNotFound, "built-in", ""))
+	assert.Error(t, Register("", http.StatusBadRequest, "empty", ""))
+	assert.Error(t, Register("Invalid_Status", 999, "invalid status", ""))
+	assert.Panics(t, func() { MustRegister(quota, http.StatusTooManyRequests, "again", "") })
+	assert.Error(t, RegisterStatus(ErrCodeNotExistInDB, http.StatusGone))
+	assert.Equal(t, http.StatusNotFound, StatusOf(ErrCodeNotExistInDB))
+
+	assert.Equal(t, http.StatusTooManyRequests, StatusOf(quota))
+	assert.Equal(t, "quota.exceeded", NewErrorWithMsg(quota, "quota").MessageID())
+	assert.Equal(t, string(ErrCodeNotExistInDB), NewErrorWithMsg(ErrCodeNotExistInDB, "not found").MessageID())
+	assert.Equal(t, http.StatusInternalServerError, StatusOf("Unknown_Error"))
+
+	codes := Codes()
+	assert.Len(t, codes, 15)
+	assert.Equal(t, ErrCodeAlreadyExistsInDB, codes[0].Code)
+
+	catalog, err := CatalogJSON()
+	assert.NoError(t, err)
+	var infos []CodeInfo
+	assert.NoError(t, json.Unmarshal(catalog, &infos))
+	assert.Equal(t, codes, infos)
+
+	markdown := CatalogMarkdown()
+	assert.True(t, strings.HasPrefix(markdown, "| Code | Status | Description | Message ID |\n|:---|:---|:---|:---|\n| AlreadyExistsInDB_Error | 409 | Resource already exists |  |\n"))
+	assert.Contains(t, markdown, "| QuotaExceeded_Error | 429 | Quota \\| limit is exceeded | quota.exceeded |\n")
+}
+
+func TestCatalogOpenAPI(t *testing.T) {
+	catalog, err := CatalogOpenAPI()
+	assert.NoError(t, err)
+
+	var openAPI struct {
+		Components struct {
+			Schemas struct {
+				ErrorCode struct {
+					Enum []string `json:"enum"`
+				} `json:"ErrorCode"`
+			} `json:"schemas"`
+			Responses map[string]struct {
+				Description string `json:"description"`
+			} `json:"responses"`
+		} `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(catalog, &openAPI))
+	assert.Contains(t, openAPI.Components.Schemas.ErrorCode.Enum, string(ErrCodeValidationError))
+	assert.Equal(t, "404 Resource does not exist", openAPI.Components.Responses[string(ErrCodeNotExistInDB)].Description)
+}
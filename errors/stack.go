@@ -0,0 +1,138 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const stackDepth = 32
+
+// 1: enabled, 0: disabled, -1: not set yet
+var stackCapture int32 = -1
+
+var stackSkip int32
+
+// SetStackCapture will enable or disable capturing stack traces of new errors of 5xx codes
+// (default: ERROR_STACK_TRACE, or enabled unless built with echokit_nostack tag)
+func SetStackCapture(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&stackCapture, value)
+}
+
+// StackCapture returns true when new errors of 5xx codes capture stack traces
+func StackCapture() bool {
+	value := atomic.LoadInt32(&stackCapture)
+	if value < 0 {
+		value = 0
+		if stackCaptureFromEnv() {
+			value = 1
+		}
+		atomic.CompareAndSwapInt32(&stackCapture, -1, value)
+	}
+	return value == 1
+}
+
+func stackCaptureFromEnv() bool {
+	if env := os.Getenv("ERROR_STACK_TRACE"); len(env) != 0 {
+		return env == "true"
+	}
+	return defaultStackCapture
+}
+
+// SetStackSkip skips extra frames of stack traces, for helpers of service which create errors
+func SetStackSkip(skip int) {
+	atomic.StoreInt32(&stackSkip, int32(skip))
+}
+
+// stack is program counters, which are resolved to frames only when printed
+type stack []uintptr
+
+// callers returns stack of the caller of public constructor which calls newError.
+// Only 5xx codes capture it, since client errors are expected and not logged with stack traces
+func callers(code ErrorCode) stack {
+	if !StackCapture() || StatusOf(code) < http.StatusInternalServerError {
+		return nil
+	}
+	var pcs [stackDepth]uintptr
+	// skip runtime.Callers, callers, newError and the constructor
+	n := runtime.Callers(4+int(atomic.LoadInt32(&stackSkip)), pcs[:])
+	return stack(append([]uintptr(nil), pcs[:n]...))
+}
+
+func (s stack) String() string {
+	if len(s) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(s)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// Stack - get the stack trace where the Error was created, or empty string when capture is disabled
+func (err Error) Stack() string {
+	return strings.TrimPrefix(err.metadata().stack.String(), "\n")
+}
+
+// Format - %+v prints code, message and stack trace of the Error, and its causes.
+// Other verbs format the message as a string like errors without Format, so %x prints it in hex and %d is a bad verb
+func (err Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%s: %s%s", err.errorCode, err.Error(), err.metadata().stack)
+			if err.error != nil {
+				if _, ok := err.error.(fmt.Formatter); ok {
+					fmt.Fprintf(s, "\ncaused by: %+v", err.error)
+				}
+			}
+			return
+		}
+		fmt.Fprintf(s, formatDirective(s, 's'), err.Error())
+	default:
+		fmt.Fprintf(s, formatDirective(s, verb), err.Error())
+	}
+}
+
+// formatDirective rebuilds directive like "%-8x" of verb with flags, width and precision of s
+func formatDirective(s fmt.State, verb rune) string {
+	var b strings.Builder
+	b.WriteByte('%')
+	for _, flag := range "+-# 0" {
+		if s.Flag(int(flag)) {
+			b.WriteRune(flag)
+		}
+	}
+	if width, ok := s.Width(); ok {
+		b.WriteString(strconv.Itoa(width))
+	}
+	if precision, ok := s.Precision(); ok {
+		b.WriteByte('.')
+		b.WriteString(strconv.Itoa(precision))
+	}
+	b.WriteRune(verb)
+	return b.String()
+}
+
+// StackOf - get the stack trace of the first Error in the chain
+func StackOf(err error) string {
+	var e Error
+	if As(err, &e) {
+		return e.Stack()
+	}
+	return ""
+}
@@ -0,0 +1,6 @@
+//go:build !echokit_nostack
+// +build !echokit_nostack
+
+package errors
+
+const defaultStackCapture = true
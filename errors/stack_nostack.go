@@ -0,0 +1,7 @@
+//go:build echokit_nostack
+// +build echokit_nostack
+
+package errors
+
+// built with -tags echokit_nostack to disable stack capture in hot paths
+const defaultStackCapture = false
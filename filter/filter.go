@@ -0,0 +1,310 @@
+package filter
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+	"github.com/rakutentech/go-echo-kit/pagination"
+
+	echo "github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Operator of query like created_at[gte]=
+type Operator string
+
+// Operators
+const (
+	OpEq   Operator = "eq"
+	OpNe   Operator = "ne"
+	OpIn   Operator = "in"
+	OpGte  Operator = "gte"
+	OpLte  Operator = "lte"
+	OpLike Operator = "like"
+	OpNull Operator = "null"
+)
+
+// Type of field value
+type Type int
+
+// Types
+const (
+	TypeString Type = iota
+	TypeInt
+	TypeFloat
+	TypeBool
+	TypeTime
+)
+
+// Field is a filterable field of model
+type Field struct {
+	// Column name, which is quoted by gorm
+	Column string
+
+	// Type parses values (default: TypeString)
+	Type Type
+
+	// Operators allowed for the field (default: all operators)
+	Operators []Operator
+}
+
+// Config declares allow-listed fields of model
+type Config struct {
+	// Fields maps field of query to Field
+	Fields map[string]Field
+
+	// Ignore is query parameters which are not filters, in addition to DefaultIgnore
+	Ignore []string
+
+	// MaxInValues is the max number of values of in operator (default: DefaultMaxInValues)
+	MaxInValues int
+
+	// TimeParser parses values of TypeTime (default: 2006-01-02 or RFC3339). CustomContext.Filter uses context.ParseTime
+	TimeParser func(value string) (time.Time, error)
+
+	// EndTimeParser parses values of TypeTime for lte, so that date only values include the whole day
+	// (default: TimeParser, or the end of the day for 2006-01-02 without TimeParser). CustomContext.Filter uses context.ParseTimeEnd
+	EndTimeParser func(value string) (time.Time, error)
+}
+
+// QueryLang is the language parameter of messages.Middleware
+const QueryLang = "lang"
+
+// DefaultIgnore is pagination and language parameters
+var DefaultIgnore = []string{
+	pagination.QueryPage, pagination.QueryPerPage, pagination.QuerySort, pagination.QueryCursor,
+	QueryLang,
+}
+
+// DefaultMaxInValues ...
+const DefaultMaxInValues = 100
+
+// likeEscape is the escape character of LIKE, which has the same meaning in all databases unlike backslash
+const likeEscape = "!"
+
+// Condition is a parsed filter
+type Condition struct {
+	Column   string
+	Operator Operator
+	Value    interface{}
+}
+
+// Filter is parsed conditions of request
+type Filter struct {
+	Conditions []Condition
+}
+
+// Parse parses ?status=active&created_at[gte]=2024-01-01&name[like]=foo of request.
+// Unknown fields, operators and bad values return errors.Error with ErrCodeValidationError
+func Parse(c echo.Context, config Config) (Filter, error) {
+	ignore := append(append([]string{}, DefaultIgnore...), config.Ignore...)
+	if config.MaxInValues <= 0 {
+		config.MaxInValues = DefaultMaxInValues
+	}
+	if config.EndTimeParser == nil {
+		config.EndTimeParser = config.TimeParser
+	}
+	if config.TimeParser == nil {
+		config.TimeParser = parseTime
+	}
+	if config.EndTimeParser == nil {
+		config.EndTimeParser = parseTimeEnd
+	}
+
+	filter := Filter{}
+	for key, values := range c.QueryParams() {
+		if contains(ignore, key) {
+			continue
+		}
+		name, operator, err := parseKey(key)
+		if err != nil {
+			return filter, err
+		}
+		field, ok := config.Fields[name]
+		if !ok {
+			return filter, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s is not allowed to filter", name)
+		}
+		if len(field.Operators) > 0 && !containsOperator(field.Operators, operator) {
+			return filter, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s is not allowed for %s", operator, name)
+		}
+
+		for _, value := range values {
+			condition, err := newCondition(config, name, field, operator, value)
+			if err != nil {
+				return filter, err
+			}
+			filter.Conditions = append(filter.Conditions, condition)
+		}
+	}
+
+	// query map has random order
+	sortConditions(filter.Conditions)
+	return filter, nil
+}
+
+// parseKey splits "created_at[gte]" into field and operator
+func parseKey(key string) (string, Operator, error) {
+	open := strings.Index(key, "[")
+	if open < 0 {
+		return key, OpEq, nil
+	}
+	if !strings.HasSuffix(key, "]") {
+		return "", "", errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "invalid filter: %s", key)
+	}
+
+	operator := Operator(key[open+1 : len(key)-1])
+	switch operator {
+	case OpEq, OpNe, OpIn, OpGte, OpLte, OpLike, OpNull:
+		return key[:open], operator, nil
+	}
+	return "", "", errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "unknown operator: %s", operator)
+}
+
+func newCondition(config Config, name string, field Field, operator Operator, value string) (Condition, error) {
+	condition := Condition{Column: field.Column, Operator: operator}
+	if condition.Column == "" {
+		condition.Column = name
+	}
+
+	switch operator {
+	case OpNull:
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return condition, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s[%s] must be true or false: %s", name, operator, value)
+		}
+		condition.Value = isNull
+	case OpIn:
+		items := strings.Split(value, ",")
+		if len(items) > config.MaxInValues {
+			return condition, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s[%s] must have at most %d values", name, operator, config.MaxInValues)
+		}
+		var list []interface{}
+		for _, item := range items {
+			parsed, err := parseValue(config, field.Type, item)
+			if err != nil {
+				return condition, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s[%s] is invalid: %s", name, operator, err.Error())
+			}
+			list = append(list, parsed)
+		}
+		condition.Value = list
+	case OpLike:
+		if field.Type != TypeString {
+			return condition, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s[%s] is only for string fields", name, operator)
+		}
+		condition.Value = "%" + escapeLike(value) + "%"
+	case OpLte:
+		parse := config
+		parse.TimeParser = config.EndTimeParser
+		parsed, err := parseValue(parse, field.Type, value)
+		if err != nil {
+			return condition, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s[%s] is invalid: %s", name, operator, err.Error())
+		}
+		condition.Value = parsed
+	default:
+		parsed, err := parseValue(config, field.Type, value)
+		if err != nil {
+			return condition, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s[%s] is invalid: %s", name, operator, err.Error())
+		}
+		condition.Value = parsed
+	}
+	return condition, nil
+}
+
+func parseValue(config Config, t Type, value string) (interface{}, error) {
+	switch t {
+	case TypeInt:
+		return strconv.ParseInt(value, 10, 64)
+	case TypeFloat:
+		return strconv.ParseFloat(value, 64)
+	case TypeBool:
+		return strconv.ParseBool(value)
+	case TypeTime:
+		return config.TimeParser(value)
+	}
+	return value, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if parsed, err := time.Parse("2006-01-02", value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseTimeEnd is parseTime which returns the end of the day for 2006-01-02
+func parseTimeEnd(value string) (time.Time, error) {
+	if parsed, err := time.Parse("2006-01-02", value); err == nil {
+		return parsed.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// escapeLike escapes wildcards of LIKE with likeEscape so values match literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(likeEscape, likeEscape+likeEscape, `%`, likeEscape+`%`, `_`, likeEscape+`_`).Replace(value)
+}
+
+// Expression returns gorm clause of condition
+func (condition Condition) Expression() clause.Expression {
+	column := clause.Column{Name: condition.Column}
+	switch condition.Operator {
+	case OpNe:
+		return clause.Neq{Column: column, Value: condition.Value}
+	case OpIn:
+		return clause.IN{Column: column, Values: condition.Value.([]interface{})}
+	case OpGte:
+		return clause.Gte{Column: column, Value: condition.Value}
+	case OpLte:
+		return clause.Lte{Column: column, Value: condition.Value}
+	case OpLike:
+		return clause.Expr{SQL: "? LIKE ? ESCAPE '" + likeEscape + "'", Vars: []interface{}{column, condition.Value}}
+	case OpNull:
+		if condition.Value.(bool) {
+			return clause.Eq{Column: column, Value: nil}
+		}
+		return clause.Neq{Column: column, Value: nil}
+	}
+	return clause.Eq{Column: column, Value: condition.Value}
+}
+
+// Scope applies conditions to query as Where clauses.
+// Usage conn.Scopes(f.Scope(), p.Scope()).Find(&users)
+func (f Filter) Scope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, condition := range f.Conditions {
+			db = db.Where(condition.Expression())
+		}
+		return db
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOperator(operators []Operator, operator Operator) bool {
+	for _, o := range operators {
+		if o == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func sortConditions(conditions []Condition) {
+	sort.SliceStable(conditions, func(i, j int) bool {
+		if conditions[i].Column != conditions[j].Column {
+			return conditions[i].Column < conditions[j].Column
+		}
+		return conditions[i].Operator < conditions[j].Operator
+	})
+}
@@ -0,0 +1,122 @@
+package filter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+type user struct {
+	ID        uint64
+	Name      string
+	Status    string
+	Age       int
+	DeletedAt *time.Time
+	CreatedAt time.Time
+}
+
+var testConfig = Config{
+	Fields: map[string]Field{
+		"status":     {Column: "status", Operators: []Operator{OpEq, OpNe, OpIn}},
+		"name":       {Column: "name"},
+		"age":        {Column: "age", Type: TypeInt},
+		"deleted_at": {Column: "deleted_at", Type: TypeTime},
+		"created_at": {Column: "created_at", Type: TypeTime},
+	},
+}
+
+func newContext(target string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func openDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true, SkipDefaultTransaction: true})
+	assert.NoError(t, err)
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return db
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		haveQuery string
+		wantSQL   string
+		wantVars  []interface{}
+	}{
+		{"", "SELECT * FROM `users`", nil},
+		{"?status=active&page=2&sort=-id", "SELECT * FROM `users` WHERE `status` = ?", []interface{}{"active"}},
+		{"?status[ne]=banned", "SELECT * FROM `users` WHERE `status` <> ?", []interface{}{"banned"}},
+		{"?status[in]=active,pending", "SELECT * FROM `users` WHERE `status` IN (?,?)", []interface{}{"active", "pending"}},
+		{"?age[gte]=20&age[lte]=30", "SELECT * FROM `users` WHERE `age` >= ? AND `age` <= ?", []interface{}{int64(20), int64(30)}},
+		{"?name[like]=50%25_off!", "SELECT * FROM `users` WHERE `name` LIKE ? ESCAPE '!'", []interface{}{`%50!%!_off!!%`}},
+		{"?status=active&lang=ja", "SELECT * FROM `users` WHERE `status` = ?", []interface{}{"active"}},
+		{"?deleted_at[null]=true", "SELECT * FROM `users` WHERE `deleted_at` IS NULL", nil},
+		{"?deleted_at[null]=false", "SELECT * FROM `users` WHERE `deleted_at` IS NOT NULL", nil},
+		{"?created_at[gte]=2024-01-01", "SELECT * FROM `users` WHERE `created_at` >= ?", []interface{}{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{"?created_at[lte]=2024-01-31", "SELECT * FROM `users` WHERE `created_at` <= ?", []interface{}{time.Date(2024, 1, 31, 23, 59, 59, 999999999, time.UTC)}},
+		{"?created_at[lte]=2024-01-31T10:00:00Z", "SELECT * FROM `users` WHERE `created_at` <= ?", []interface{}{time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)}},
+	}
+
+	for _, tt := range tests {
+		f, err := Parse(newContext("/users"+tt.haveQuery), testConfig)
+		assert.NoError(t, err, tt.haveQuery)
+
+		stmt := openDryRunDB(t).Scopes(f.Scope()).Find(&[]user{}).Statement
+		assert.Equal(t, tt.wantSQL, stmt.SQL.String(), tt.haveQuery)
+		if len(tt.wantVars) > 0 || len(stmt.Vars) > 0 {
+			assert.Equal(t, tt.wantVars, stmt.Vars, tt.haveQuery)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	config := testConfig
+	config.Ignore = []string{"fields"}
+	config.MaxInValues = 2
+	config.TimeParser = func(value string) (time.Time, error) {
+		return time.Parse("20060102", value)
+	}
+
+	f, err := Parse(newContext("/users?fields=id&page=2&status[in]=a,b&created_at[gte]=20240501"), config)
+	assert.NoError(t, err)
+	assert.Equal(t, []Condition{
+		{Column: "created_at", Operator: OpGte, Value: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Column: "status", Operator: OpIn, Value: []interface{}{"a", "b"}},
+	}, f.Conditions)
+
+	_, err = Parse(newContext("/users?status[in]=a,b,c"), config)
+	assert.EqualError(t, err, "status[in] must have at most 2 values")
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		haveQuery string
+	}{
+		{"?password=secret"},
+		{"?name[regexp]=a"},
+		{"?name[eq=a"},
+		{"?status[like]=act"},
+		{"?age=twenty"},
+		{"?age[like]=2"},
+		{"?age[in]=1,a"},
+		{"?deleted_at[null]=yes"},
+		{"?created_at[gte]=yesterday"},
+		{"?`name`=a"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(newContext("/users"+tt.haveQuery), testConfig)
+		if assert.Error(t, err, tt.haveQuery) {
+			assert.Equal(t, string(errors.ErrCodeValidationError), err.(errors.Error).ErrorCode(), tt.haveQuery)
+		}
+	}
+}
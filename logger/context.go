@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"context"
+	"strings"
+)
+
+type requestIDKey struct{}
+
+var contextFields []func(ctx context.Context) string
+
+// AddContextField adds function which returns "key=value" field of context (or empty string) to ContextFields
+// It is not safe to call while logging
+func AddContextField(field func(ctx context.Context) string) {
+	contextFields = append(contextFields, field)
+}
+
+// ContextFields returns "request_id=... key=value ..." fields of context for loggers which know context.
+// Fields without value are omitted, so it returns empty string for context without fields
+func ContextFields(ctx context.Context) string {
+	var fields []string
+	if requestID := RequestID(ctx); requestID != "" {
+		fields = append(fields, "request_id="+requestID)
+	}
+	if ctx != nil {
+		for _, field := range contextFields {
+			if f := field(ctx); f != "" {
+				fields = append(fields, f)
+			}
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// WithRequestID returns context with request ID which loggers like SQL logger print
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns request ID in context, or empty string
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,45 @@
+package messages
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v2"
+)
+
+// NewBundle creates i18n.Bundle which can load TOML, YAML and JSON message files
+func NewBundle(defaultLanguage language.Tag) *i18n.Bundle {
+	bundle := i18n.NewBundle(defaultLanguage)
+	bundle.RegisterUnmarshalFunc("toml", toml.Unmarshal)
+	bundle.RegisterUnmarshalFunc("yaml", yaml.Unmarshal)
+	bundle.RegisterUnmarshalFunc("yml", yaml.Unmarshal)
+	return bundle
+}
+
+// isMessageFile returns true for files whose format is registered by NewBundle
+func isMessageFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml", ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// LoadDir loads message files like "active.en.toml" and "ja.yaml" in dir and its subdirectories into bundle.
+// Other files are ignored
+func LoadDir(bundle *i18n.Bundle, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !isMessageFile(path) {
+			return nil
+		}
+		_, err = bundle.LoadMessageFile(path)
+		return err
+	})
+}
@@ -0,0 +1,29 @@
+//go:build go1.16
+// +build go1.16
+
+package messages
+
+import (
+	"io/fs"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// LoadFS loads message files in dir of fsys like embed.FS into bundle, as LoadDir
+// Usage //go:embed locales; var locales embed.FS; messages.LoadFS(bundle, locales, "locales")
+func LoadFS(bundle *i18n.Bundle, fsys fs.FS, dir string) error {
+	return fs.WalkDir(fsys, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isMessageFile(path) {
+			return nil
+		}
+		buf, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		_, err = bundle.ParseMessageFileBytes(buf, path)
+		return err
+	})
+}
@@ -0,0 +1,28 @@
+//go:build go1.16
+// +build go1.16
+
+package messages
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, content := range testMessageFiles {
+		fsys["locales/"+name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	bundle := NewBundle(language.English)
+	assert.NoError(t, LoadFS(bundle, fsys, "locales"))
+	assert.ElementsMatch(t, []language.Tag{language.English, language.Japanese, language.French}, bundle.LanguageTags())
+
+	msg, err := i18n.NewLocalizer(bundle, "ja").Localize(&i18n.LocalizeConfig{MessageID: "hello"})
+	assert.NoError(t, err)
+	assert.Equal(t, "こんにちは", msg)
+}
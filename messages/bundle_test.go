@@ -0,0 +1,63 @@
+package messages
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+var testMessageFiles = map[string]string{
+	"active.en.toml": "hello = \"Hello\"\n\n[welcome]\nother = \"Welcome {{.Name}}\"\n",
+	"ja.yaml":        "hello: こんにちは\nwelcome: ようこそ {{.Name}}\n",
+	"nested/fr.json": `{"hello": "Bonjour"}`,
+	"README.md":      "not a message file",
+}
+
+func newTestBundle(t *testing.T) *i18n.Bundle {
+	dir, err := ioutil.TempDir("", "messages")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for name, content := range testMessageFiles {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	bundle := NewBundle(language.English)
+	assert.NoError(t, LoadDir(bundle, dir))
+	return bundle
+}
+
+func TestLoadDir(t *testing.T) {
+	bundle := newTestBundle(t)
+	assert.ElementsMatch(t, []language.Tag{language.English, language.Japanese, language.French}, bundle.LanguageTags())
+
+	tests := []struct {
+		haveLang string
+		haveID   string
+		wantMsg  string
+	}{
+		{"en", "hello", "Hello"},
+		{"en", "welcome", "Welcome Gopher"},
+		{"ja", "hello", "こんにちは"},
+		{"ja", "welcome", "ようこそ Gopher"},
+		{"fr", "hello", "Bonjour"},
+	}
+
+	for _, tt := range tests {
+		msg, err := i18n.NewLocalizer(bundle, tt.haveLang).Localize(&i18n.LocalizeConfig{
+			MessageID:    tt.haveID,
+			TemplateData: map[string]interface{}{"Name": "Gopher"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, tt.wantMsg, msg, tt.haveLang+" "+tt.haveID)
+	}
+
+	assert.Error(t, LoadDir(NewBundle(language.English), "not_found"))
+}
@@ -1,43 +1,91 @@
 package messages
 
 import (
+	"errors"
+
 	echo "github.com/labstack/echo/v4"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
 	"gopkg.in/go-playground/validator.v9"
 )
 
 // Message ...
 type Message struct {
 	localizer *i18n.Localizer
+	language  language.Tag
 }
 
 // NewMessage ...
 func NewMessage(c echo.Context) Message {
-	localizer, _ := c.Get("localizer").(*i18n.Localizer)
+	localizer, _ := c.Get(LocalizerKey).(*i18n.Localizer)
 	m := Message{
 		localizer: localizer,
+		language:  language.Und,
+	}
+	if c.Request() != nil {
+		if m.localizer == nil {
+			m.localizer = LocalizerFrom(c.Request().Context())
+		}
+		m.language = LanguageFrom(c.Request().Context())
 	}
 	return m
 }
 
-// GetMessage ...
+// GetMessage returns message of id in requested language, then in default language of bundle, then id itself.
+// Messages which are not found in requested language are reported to the handler of SetMissingHandler
 func (m *Message) GetMessage(id string, templateData map[string]interface{}) string {
-	msg, _ := m.localizer.Localize(&i18n.LocalizeConfig{
-		DefaultMessage: &i18n.Message{
-			ID: id,
-		},
-		TemplateData: templateData,
-	})
+	msg, found := m.localize(&i18n.LocalizeConfig{MessageID: id, TemplateData: templateData}, true)
+	if !found {
+		return id
+	}
+	return msg
+}
+
+// GetPluralMessage returns plural form of message for count like GetMessage. Count is {{.PluralCount}} of templates
+func (m *Message) GetPluralMessage(id string, count interface{}, templateData map[string]interface{}) string {
+	data := make(map[string]interface{}, len(templateData)+1)
+	for k, v := range templateData {
+		data[k] = v
+	}
+	data["PluralCount"] = count
+
+	msg, found := m.localize(&i18n.LocalizeConfig{MessageID: id, TemplateData: data, PluralCount: count}, true)
+	if !found {
+		return id
+	}
 	return msg
 }
 
-// GetFieldTagMessage ...
+// Lookup returns message of id in requested or default language, and false when it is not found.
+// It does not report missing messages, for optional messages which callers fall back from
+func (m *Message) Lookup(id string, templateData map[string]interface{}) (string, bool) {
+	return m.localize(&i18n.LocalizeConfig{MessageID: id, TemplateData: templateData}, false)
+}
+
+// GetFieldTagMessage returns message of "Field.tag", then "tag", or empty string when both are not found
 func (m *Message) GetFieldTagMessage(e validator.FieldError, templateData map[string]interface{}) string {
 	key := e.Field() + "." + e.Tag()
-	msg := m.GetMessage(key, templateData)
-
-	if len(msg) == 0 {
-		msg = m.GetMessage(e.Tag(), templateData)
+	if msg, found := m.Lookup(key, templateData); found {
+		return msg
 	}
+
+	msg, _ := m.localize(&i18n.LocalizeConfig{MessageID: e.Tag(), TemplateData: templateData}, true)
 	return msg
 }
+
+// localize returns message and whether it is found, reporting missing translations when report is true
+func (m *Message) localize(config *i18n.LocalizeConfig, report bool) (string, bool) {
+	if m == nil || m.localizer == nil {
+		return "", false
+	}
+
+	msg, tag, err := m.localizer.LocalizeWithTag(config)
+	found := tag != language.Und
+	if err != nil && report {
+		var notFound *i18n.MessageNotFoundErr
+		if errors.As(err, &notFound) {
+			reportMissing(MissingTranslation{MessageID: config.MessageID, Language: m.language, Fallback: tag})
+		}
+	}
+	return msg, found
+}
@@ -0,0 +1,144 @@
+package messages
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+func newTestMessage(lang string) Message {
+	bundle := NewBundle(language.English)
+	bundle.MustAddMessages(language.English,
+		&i18n.Message{ID: "hello", Other: "Hello {{.Name}}"},
+		&i18n.Message{ID: "bye", Other: "Bye"},
+		&i18n.Message{ID: "items", One: "{{.PluralCount}} item in {{.Cart}}", Other: "{{.PluralCount}} items in {{.Cart}}"},
+		&i18n.Message{ID: "required", Other: "{{.Field}} is required"},
+		&i18n.Message{ID: "Email.required", Other: "Email address is required"},
+	)
+	bundle.MustAddMessages(language.Japanese,
+		&i18n.Message{ID: "hello", Other: "こんにちは {{.Name}}"},
+		&i18n.Message{ID: "items", Other: "{{.Cart}}に{{.PluralCount}}個"},
+	)
+
+	var m Message
+	e := echo.New()
+	e.Use(Middleware(bundle))
+	e.GET("/", func(c echo.Context) error {
+		m = NewMessage(c)
+		return nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/?lang="+lang, nil)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	return m
+}
+
+type missingRecorder struct {
+	mu      sync.Mutex
+	missing []MissingTranslation
+}
+
+func (r *missingRecorder) handle(missing MissingTranslation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.missing = append(r.missing, missing)
+}
+
+func TestGetMessage(t *testing.T) {
+	recorder := &missingRecorder{}
+	SetMissingHandler(recorder.handle)
+	defer SetMissingHandler(nil)
+
+	tests := []struct {
+		haveLang    string
+		haveID      string
+		wantMsg     string
+		wantMissing []MissingTranslation
+	}{
+		{"ja", "hello", "こんにちは Gopher", nil},
+		{"en", "hello", "Hello Gopher", nil},
+		{"ja", "bye", "Bye", []MissingTranslation{{MessageID: "bye", Language: language.Japanese, Fallback: language.English}}},
+		{"ja", "unknown", "unknown", []MissingTranslation{{MessageID: "unknown", Language: language.Japanese, Fallback: language.Und}}},
+		{"en", "unknown", "unknown", []MissingTranslation{{MessageID: "unknown", Language: language.English, Fallback: language.Und}}},
+	}
+
+	for _, tt := range tests {
+		recorder.missing = nil
+		m := newTestMessage(tt.haveLang)
+		assert.Equal(t, tt.wantMsg, m.GetMessage(tt.haveID, map[string]interface{}{"Name": "Gopher"}), tt.haveLang+" "+tt.haveID)
+		assert.Equal(t, tt.wantMissing, recorder.missing, tt.haveLang+" "+tt.haveID)
+	}
+}
+
+func TestGetPluralMessage(t *testing.T) {
+	tests := []struct {
+		haveLang  string
+		haveCount interface{}
+		wantMsg   string
+	}{
+		{"en", 1, "1 item in cart"},
+		{"en", 2, "2 items in cart"},
+		{"en", "1.5", "1.5 items in cart"},
+		{"ja", 1, "cartに1個"},
+	}
+
+	for _, tt := range tests {
+		m := newTestMessage(tt.haveLang)
+		assert.Equal(t, tt.wantMsg, m.GetPluralMessage("items", tt.haveCount, map[string]interface{}{"Cart": "cart"}), tt.haveLang)
+	}
+	m := newTestMessage("en")
+	assert.Equal(t, "unknown", m.GetPluralMessage("unknown", 2, nil))
+}
+
+func TestLookup(t *testing.T) {
+	recorder := &missingRecorder{}
+	SetMissingHandler(recorder.handle)
+	defer SetMissingHandler(nil)
+
+	m := newTestMessage("ja")
+	msg, found := m.Lookup("bye", nil)
+	assert.True(t, found)
+	assert.Equal(t, "Bye", msg)
+
+	msg, found = m.Lookup("unknown", nil)
+	assert.False(t, found)
+	assert.Equal(t, "", msg)
+	assert.Empty(t, recorder.missing)
+}
+
+func TestGetFieldTagMessage(t *testing.T) {
+	type request struct {
+		Email string `validate:"required"`
+		Name  string `validate:"required"`
+		Age   int    `validate:"min=20"`
+	}
+	err := validator.New().Struct(request{Age: 1})
+	fieldErrors := err.(validator.ValidationErrors)
+
+	m := newTestMessage("en")
+	var got []string
+	for _, fe := range fieldErrors {
+		got = append(got, m.GetFieldTagMessage(fe, map[string]interface{}{"Field": fe.Field()}))
+	}
+	assert.Equal(t, []string{"Email address is required", "Name is required", ""}, got)
+}
+
+func TestNilMessage(t *testing.T) {
+	var nilMessage *Message
+	assert.Equal(t, "hello", nilMessage.GetMessage("hello", nil))
+	assert.Equal(t, "items", nilMessage.GetPluralMessage("items", 1, nil))
+
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	m := NewMessage(c)
+	assert.Equal(t, "hello", m.GetMessage("hello", nil))
+	msg, found := m.Lookup("hello", nil)
+	assert.False(t, found)
+	assert.Equal(t, "", msg)
+}
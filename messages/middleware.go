@@ -0,0 +1,139 @@
+package messages
+
+import (
+	"context"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+)
+
+// LocalizerKey is the key of echo context where Middleware sets *i18n.Localizer for NewMessage
+const LocalizerKey = "localizer"
+
+type localizerKey struct{}
+
+type languageKey struct{}
+
+// MiddlewareConfig defines the config for i18n middleware
+type MiddlewareConfig struct {
+	// Skipper defines a function to skip middleware
+	Skipper middleware.Skipper
+
+	// Bundle has messages of languages, whose default language is used when no language matches (required).
+	// Messages must be loaded before creating middleware
+	Bundle *i18n.Bundle
+
+	// QueryParam is query parameter of language, which precedes cookie and Accept-Language (empty to disable)
+	QueryParam string
+
+	// CookieName is cookie of language, which precedes Accept-Language (empty to disable)
+	CookieName string
+}
+
+// DefaultMiddlewareConfig ...
+var DefaultMiddlewareConfig = MiddlewareConfig{
+	Skipper:    middleware.DefaultSkipper,
+	QueryParam: "lang",
+	CookieName: "lang",
+}
+
+// Middleware negotiates language of requests with DefaultMiddlewareConfig
+// Usage e.Use(messages.Middleware(bundle))
+func Middleware(bundle *i18n.Bundle) echo.MiddlewareFunc {
+	config := DefaultMiddlewareConfig
+	config.Bundle = bundle
+	return MiddlewareWithConfig(config)
+}
+
+// MiddlewareWithConfig negotiates language of requests from query parameter, cookie and Accept-Language header.
+// The localizer is stored in request context and echo context, and the language is set to Content-Language header
+func MiddlewareWithConfig(config MiddlewareConfig) echo.MiddlewareFunc {
+	if config.Bundle == nil {
+		panic("echo: i18n middleware requires bundle")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultMiddlewareConfig.Skipper
+	}
+	matcher := language.NewMatcher(config.Bundle.LanguageTags())
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			langs := requestLanguages(c, config)
+			tag := matchLanguage(matcher, config.Bundle.LanguageTags(), langs)
+			localizer := i18n.NewLocalizer(config.Bundle, langs...)
+
+			req := c.Request()
+			ctx := WithLocalizer(req.Context(), localizer)
+			ctx = context.WithValue(ctx, languageKey{}, tag)
+			c.SetRequest(req.WithContext(ctx))
+			c.Set(LocalizerKey, localizer)
+			c.Response().Header().Set("Content-Language", tag.String())
+			return next(c)
+		}
+	}
+}
+
+// requestLanguages returns languages of request in order of preference
+func requestLanguages(c echo.Context, config MiddlewareConfig) []string {
+	var langs []string
+	if config.QueryParam != "" {
+		if lang := c.QueryParam(config.QueryParam); lang != "" {
+			langs = append(langs, lang)
+		}
+	}
+	if config.CookieName != "" {
+		if cookie, err := c.Cookie(config.CookieName); err == nil && cookie.Value != "" {
+			langs = append(langs, cookie.Value)
+		}
+	}
+	if accept := c.Request().Header.Get("Accept-Language"); accept != "" {
+		langs = append(langs, accept)
+	}
+	return langs
+}
+
+// matchLanguage returns the language of bundle which matches langs best, or the default language
+func matchLanguage(matcher language.Matcher, supported []language.Tag, langs []string) language.Tag {
+	var tags []language.Tag
+	for _, lang := range langs {
+		parsed, _, err := language.ParseAcceptLanguage(lang)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, parsed...)
+	}
+	_, index, _ := matcher.Match(tags...)
+	return supported[index]
+}
+
+// WithLocalizer returns context with localizer
+func WithLocalizer(ctx context.Context, localizer *i18n.Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, localizer)
+}
+
+// LocalizerFrom returns localizer in context, or nil
+func LocalizerFrom(ctx context.Context) *i18n.Localizer {
+	if ctx == nil {
+		return nil
+	}
+	localizer, _ := ctx.Value(localizerKey{}).(*i18n.Localizer)
+	return localizer
+}
+
+// LanguageFrom returns language negotiated by Middleware, or language.Und
+func LanguageFrom(ctx context.Context) language.Tag {
+	if ctx == nil {
+		return language.Und
+	}
+	tag, ok := ctx.Value(languageKey{}).(language.Tag)
+	if !ok {
+		return language.Und
+	}
+	return tag
+}
@@ -0,0 +1,73 @@
+package messages
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestMiddleware(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	tests := []struct {
+		haveTarget string
+		haveCookie string
+		haveAccept string
+		wantLang   language.Tag
+		wantMsg    string
+	}{
+		{"/", "", "", language.English, "Hello"},
+		{"/", "", "ja-JP,ja;q=0.9,en;q=0.8", language.Japanese, "こんにちは"},
+		{"/", "fr", "ja", language.French, "Bonjour"},
+		{"/?lang=ja", "fr", "en", language.Japanese, "こんにちは"},
+		{"/?lang=de", "", "fr", language.French, "Bonjour"},
+		{"/?lang=!!", "", "", language.English, "Hello"},
+		{"/", "", "de", language.English, "Hello"},
+	}
+
+	for _, tt := range tests {
+		e := echo.New()
+		e.Use(Middleware(bundle))
+		e.GET("/", func(c echo.Context) error {
+			assert.Equal(t, tt.wantLang, LanguageFrom(c.Request().Context()), tt.haveTarget)
+			assert.NotNil(t, LocalizerFrom(c.Request().Context()))
+			m := NewMessage(c)
+			return c.String(http.StatusOK, m.GetMessage("hello", nil))
+		})
+
+		req := httptest.NewRequest(http.MethodGet, tt.haveTarget, nil)
+		if tt.haveCookie != "" {
+			req.AddCookie(&http.Cookie{Name: "lang", Value: tt.haveCookie})
+		}
+		if tt.haveAccept != "" {
+			req.Header.Set("Accept-Language", tt.haveAccept)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.wantMsg, rec.Body.String(), tt.haveTarget)
+		assert.Equal(t, tt.wantLang.String(), rec.Header().Get("Content-Language"), tt.haveTarget)
+	}
+}
+
+func TestMiddlewareWithConfig(t *testing.T) {
+	bundle := newTestBundle(t)
+	e := echo.New()
+	e.Use(MiddlewareWithConfig(MiddlewareConfig{Bundle: bundle}))
+	e.GET("/", func(c echo.Context) error {
+		assert.Equal(t, language.English, LanguageFrom(c.Request().Context()))
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/?lang=ja", nil)
+	req.AddCookie(&http.Cookie{Name: "lang", Value: "ja"})
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Panics(t, func() { MiddlewareWithConfig(MiddlewareConfig{}) })
+	assert.Equal(t, language.Und, LanguageFrom(nil))
+	assert.Nil(t, LocalizerFrom(nil))
+}
@@ -0,0 +1,42 @@
+package messages
+
+import (
+	"sync/atomic"
+
+	"github.com/rakutentech/go-echo-kit/logger"
+	"golang.org/x/text/language"
+)
+
+// MissingTranslation is a message which is not found in requested language
+type MissingTranslation struct {
+	MessageID string
+
+	// Language is requested language negotiated by Middleware, or language.Und
+	Language language.Tag
+
+	// Fallback is default language whose message is used instead, or language.Und when message ID is used
+	Fallback language.Tag
+}
+
+// MissingHandler is called for every missing translation, and must be safe for concurrent use
+type MissingHandler func(missing MissingTranslation)
+
+var missingHandler atomic.Value
+
+// SetMissingHandler sets handler of missing translations, like LogMissing or counter of metrics (nil to disable)
+func SetMissingHandler(handler MissingHandler) {
+	missingHandler.Store(handler)
+}
+
+// LogMissing logs missing translation as warning
+// Usage messages.SetMissingHandler(messages.LogMissing)
+func LogMissing(missing MissingTranslation) {
+	logger.LogWarnf("[i18n] message %q is not found in language %q, fallback %q",
+		missing.MessageID, missing.Language, missing.Fallback)
+}
+
+func reportMissing(missing MissingTranslation) {
+	if handler, _ := missingHandler.Load().(MissingHandler); handler != nil {
+		handler(missing)
+	}
+}
@@ -0,0 +1,144 @@
+package pagination
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+
+	"gorm.io/gorm/schema"
+)
+
+var schemaCache = &sync.Map{}
+
+// cursorTimeKey tags time values like {"time": "2024-05-01T10:00:00Z"}, so that they are decoded as time.Time
+const cursorTimeKey = "time"
+
+// Cursor is values of sort columns of the last row in previous page.
+// Values are numbers, strings, bools, time.Time or nil for NULL
+type Cursor struct {
+	Values []interface{}
+}
+
+// Encode returns opaque cursor string for query
+func (cursor Cursor) Encode() string {
+	values := make([]interface{}, len(cursor.Values))
+	for i, value := range cursor.Values {
+		if t, ok := value.(time.Time); ok {
+			value = map[string]string{cursorTimeKey: t.Format(time.RFC3339Nano)}
+		}
+		values[i] = value
+	}
+	data, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes cursor string created by Encode
+func DecodeCursor(value string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, errors.NewErrorWithMsg(errors.ErrCodeValidationError, "invalid cursor")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var values []interface{}
+	if err := decoder.Decode(&values); err != nil {
+		return Cursor{}, errors.NewErrorWithMsg(errors.ErrCodeValidationError, "invalid cursor")
+	}
+	for i, value := range values {
+		switch value := value.(type) {
+		case string, bool, nil:
+		case json.Number:
+			if integer, err := value.Int64(); err == nil {
+				values[i] = integer
+			} else if float, err := value.Float64(); err == nil {
+				values[i] = float
+			}
+		case map[string]interface{}:
+			t, err := decodeCursorTime(value)
+			if err != nil {
+				return Cursor{}, errors.NewErrorWithMsg(errors.ErrCodeValidationError, "invalid cursor")
+			}
+			values[i] = t
+		default:
+			return Cursor{}, errors.NewErrorWithMsg(errors.ErrCodeValidationError, "invalid cursor")
+		}
+	}
+	return Cursor{Values: values}, nil
+}
+
+func decodeCursorTime(value map[string]interface{}) (time.Time, error) {
+	text, ok := value[cursorTimeKey].(string)
+	if !ok || len(value) != 1 {
+		return time.Time{}, fmt.Errorf("unknown cursor value: %v", value)
+	}
+	return time.Parse(time.RFC3339Nano, text)
+}
+
+// NextCursor trims rows fetched by CursorScope to PerPage, and returns cursor of the next page,
+// or empty string for the last page. rows must be a pointer to slice of gorm models
+func (p Pagination) NextCursor(rows interface{}) (string, error) {
+	value := reflect.ValueOf(rows)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Slice {
+		return "", fmt.Errorf("rows must be a pointer to slice: %T", rows)
+	}
+	slice := value.Elem()
+	if slice.Len() <= p.PerPage {
+		return "", nil
+	}
+	slice.SetLen(p.PerPage)
+
+	s, err := schema.Parse(rows, schemaCache, schema.NamingStrategy{})
+	if err != nil {
+		return "", err
+	}
+	last := reflect.Indirect(slice.Index(p.PerPage - 1))
+	cursor := Cursor{}
+	for _, sort := range p.Sorts {
+		field := s.LookUpField(fieldName(sort.Column))
+		if field == nil {
+			return "", fmt.Errorf("%s is not a field of %s", sort.Column, s.Name)
+		}
+		fieldValue, _ := field.ValueOf(last)
+		cursorValue, err := cursorValueOf(fieldValue)
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, cursorValue)
+	}
+	return cursor.Encode(), nil
+}
+
+// cursorValueOf dereferences pointers, and converts values like sql.NullTime to time.Time or nil
+func cursorValueOf(value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if valuer, ok := v.Interface().(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return v.Interface(), nil
+}
+
+// fieldName returns column name without table and quotes like users.created_at
+func fieldName(column string) string {
+	if idx := strings.LastIndex(column, "."); idx >= 0 {
+		column = column[idx+1:]
+	}
+	return strings.Trim(column, "`\"")
+}
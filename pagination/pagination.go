@@ -0,0 +1,230 @@
+package pagination
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+
+	echo "github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Query parameters
+const (
+	QueryPage    = "page"
+	QueryPerPage = "per_page"
+	QuerySort    = "sort"
+	QueryCursor  = "cursor"
+)
+
+// Config defines limits and allow-listed sort fields of pagination
+type Config struct {
+	// DefaultPerPage is used when per_page is not given (default: 20)
+	DefaultPerPage int
+
+	// MaxPerPage is the upper limit of per_page (default: 100)
+	MaxPerPage int
+
+	// SortFields maps sort field of query to column. Sort fields not in this map are rejected
+	SortFields map[string]string
+
+	// DefaultSort is used when sort is not given like "-created_at"
+	DefaultSort string
+
+	// TieBreaker is unique column appended to sort for stable order and keyset pagination (default: id)
+	TieBreaker string
+
+	// NullableFields are sort fields whose columns may be NULL. Cursors treat NULL as the smallest value like MySQL
+	NullableFields []string
+}
+
+// DefaultConfig ...
+var DefaultConfig = Config{
+	DefaultPerPage: 20,
+	MaxPerPage:     100,
+	TieBreaker:     "id",
+}
+
+// Sort is a column and its direction
+type Sort struct {
+	Column   string
+	Desc     bool
+	Nullable bool
+}
+
+// Pagination is parsed page, per_page, sort and cursor of request
+type Pagination struct {
+	Page    int
+	PerPage int
+	Sorts   []Sort
+	Cursor  *Cursor
+}
+
+// Parse parses ?page=&per_page=&sort=-created_at,name&cursor= of request.
+// Invalid values return errors.Error with ErrCodeValidationError
+func Parse(c echo.Context, config Config) (Pagination, error) {
+	if config.DefaultPerPage <= 0 {
+		config.DefaultPerPage = DefaultConfig.DefaultPerPage
+	}
+	if config.MaxPerPage <= 0 {
+		config.MaxPerPage = DefaultConfig.MaxPerPage
+	}
+	if config.TieBreaker == "" {
+		config.TieBreaker = DefaultConfig.TieBreaker
+	}
+
+	p := Pagination{Page: 1, PerPage: config.DefaultPerPage}
+	if value := c.QueryParam(QueryPage); value != "" {
+		// offset of the last page fits in int32
+		maxPage := math.MaxInt32 / config.MaxPerPage
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 || page > maxPage {
+			return p, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s must be between 1 and %d: %s", QueryPage, maxPage, value)
+		}
+		p.Page = page
+	}
+	if value := c.QueryParam(QueryPerPage); value != "" {
+		perPage, err := strconv.Atoi(value)
+		if err != nil || perPage < 1 || perPage > config.MaxPerPage {
+			return p, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s must be between 1 and %d: %s", QueryPerPage, config.MaxPerPage, value)
+		}
+		p.PerPage = perPage
+	}
+
+	sorts, err := parseSorts(c.QueryParam(QuerySort), config)
+	if err != nil {
+		return p, err
+	}
+	p.Sorts = sorts
+
+	if value := c.QueryParam(QueryCursor); value != "" {
+		cursor, err := DecodeCursor(value)
+		if err != nil {
+			return p, err
+		}
+		if len(cursor.Values) != len(p.Sorts) {
+			return p, errors.NewErrorWithMsg(errors.ErrCodeValidationError, "cursor does not match sort")
+		}
+		p.Cursor = &cursor
+	}
+	return p, nil
+}
+
+func parseSorts(value string, config Config) ([]Sort, error) {
+	if value == "" {
+		value = config.DefaultSort
+	}
+
+	var sorts []Sort
+	hasTieBreaker := false
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+
+		column, ok := config.SortFields[field]
+		if !ok {
+			return nil, errors.NewErrorWithMsgf(errors.ErrCodeValidationError, "%s is not allowed to sort", field)
+		}
+		if column == config.TieBreaker {
+			hasTieBreaker = true
+		}
+		sorts = append(sorts, Sort{Column: column, Desc: desc, Nullable: contains(config.NullableFields, field)})
+	}
+	if !hasTieBreaker {
+		sorts = append(sorts, Sort{Column: config.TieBreaker})
+	}
+	return sorts, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Offset returns offset of the page
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// Scope applies order, offset and limit to query.
+// Usage conn.Scopes(p.Scope()).Find(&users)
+func (p Pagination) Scope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return p.order(db).Offset(p.Offset()).Limit(p.PerPage)
+	}
+}
+
+// CursorScope applies order, keyset condition of cursor and limit to query.
+// It fetches PerPage+1 rows, so pass the result to NextCursor to know whether next page exists
+func (p Pagination) CursorScope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = p.order(db)
+		if p.Cursor != nil {
+			db = db.Where(p.keyset())
+		}
+		return db.Limit(p.PerPage + 1)
+	}
+}
+
+func (p Pagination) order(db *gorm.DB) *gorm.DB {
+	for _, sort := range p.Sorts {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: sort.Column}, Desc: sort.Desc})
+	}
+	return db
+}
+
+// keyset builds (a > ?) OR (a = ? AND b > ?) ... where > is < for desc columns.
+// NULL is the smallest value, so that it is before others in asc and after others in desc order
+func (p Pagination) keyset() clause.Expression {
+	var conditions []clause.Expression
+	for i, sort := range p.Sorts {
+		var and []clause.Expression
+		for j := 0; j < i; j++ {
+			and = append(and, equalTo(p.Sorts[j].Column, p.Cursor.Values[j]))
+		}
+		after, ok := afterOf(sort, p.Cursor.Values[i])
+		if !ok {
+			continue
+		}
+		conditions = append(conditions, clause.And(append(and, after)...))
+	}
+	if len(conditions) == 0 {
+		return clause.Expr{SQL: "1 = 0"}
+	}
+	return clause.Or(conditions...)
+}
+
+func equalTo(name string, value interface{}) clause.Expression {
+	column := clause.Column{Name: name}
+	if value == nil {
+		return clause.Expr{SQL: "? IS NULL", Vars: []interface{}{column}}
+	}
+	return clause.Eq{Column: column, Value: value}
+}
+
+// afterOf returns condition of rows after value, or false when no row is after NULL in desc order
+func afterOf(sort Sort, value interface{}) (clause.Expression, bool) {
+	column := clause.Column{Name: sort.Column}
+	switch {
+	case value == nil && sort.Desc:
+		return nil, false
+	case value == nil:
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{column}}, true
+	case sort.Desc && sort.Nullable:
+		return clause.Or(clause.Lt{Column: column, Value: value}, clause.Expr{SQL: "? IS NULL", Vars: []interface{}{column}}), true
+	case sort.Desc:
+		return clause.Lt{Column: column, Value: value}, true
+	}
+	return clause.Gt{Column: column, Value: value}, true
+}
@@ -0,0 +1,223 @@
+package pagination
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rakutentech/go-echo-kit/errors"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+type user struct {
+	ID        uint64
+	Name      string
+	CreatedAt int64
+}
+
+type post struct {
+	ID          uint64
+	CreatedAt   time.Time
+	PublishedAt *time.Time
+	DeletedAt   sql.NullTime
+}
+
+var testConfig = Config{
+	SortFields:  map[string]string{"id": "id", "name": "name", "created_at": "created_at"},
+	DefaultSort: "-created_at",
+}
+
+func newContext(target string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func openDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true, SkipDefaultTransaction: true})
+	assert.NoError(t, err)
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return db
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		haveQuery   string
+		wantPage    int
+		wantPerPage int
+		wantSorts   []Sort
+		wantErr     bool
+	}{
+		{"", 1, 20, []Sort{{Column: "created_at", Desc: true}, {Column: "id"}}, false},
+		{"?page=3&per_page=50&sort=name,-id", 3, 50, []Sort{{Column: "name"}, {Column: "id", Desc: true}}, false},
+		{"?page=0", 0, 0, nil, true},
+		{"?page=a", 0, 0, nil, true},
+		{"?page=21474836", 21474836, 20, []Sort{{Column: "created_at", Desc: true}, {Column: "id"}}, false},
+		{"?page=21474837", 0, 0, nil, true},
+		{"?page=9223372036854775807", 0, 0, nil, true},
+		{"?per_page=101", 0, 0, nil, true},
+		{"?sort=password", 0, 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		p, err := Parse(newContext("/users"+tt.haveQuery), testConfig)
+		if tt.wantErr {
+			assert.Error(t, err, tt.haveQuery)
+			assert.Equal(t, string(errors.ErrCodeValidationError), err.(errors.Error).ErrorCode())
+			continue
+		}
+		assert.NoError(t, err, tt.haveQuery)
+		assert.Equal(t, tt.wantPage, p.Page, tt.haveQuery)
+		assert.Equal(t, tt.wantPerPage, p.PerPage, tt.haveQuery)
+		assert.Equal(t, tt.wantSorts, p.Sorts, tt.haveQuery)
+	}
+}
+
+func TestScope(t *testing.T) {
+	p, err := Parse(newContext("/users?page=3&per_page=10&sort=name"), testConfig)
+	assert.NoError(t, err)
+
+	stmt := openDryRunDB(t).Scopes(p.Scope()).Find(&[]user{}).Statement
+	assert.Equal(t, "SELECT * FROM `users` ORDER BY `name`,`id` LIMIT 10 OFFSET 20", stmt.SQL.String())
+}
+
+func TestCursorScope(t *testing.T) {
+	cursor := Cursor{Values: []interface{}{int64(100), int64(7)}}
+	p, err := Parse(newContext("/users?per_page=2&cursor="+cursor.Encode()), testConfig)
+	assert.NoError(t, err)
+
+	stmt := openDryRunDB(t).Scopes(p.CursorScope()).Find(&[]user{}).Statement
+	assert.Equal(t, "SELECT * FROM `users` WHERE (`created_at` < ? OR (`created_at` = ? AND `id` > ?)) ORDER BY `created_at` DESC,`id` LIMIT 3", stmt.SQL.String())
+	assert.Equal(t, []interface{}{int64(100), int64(100), int64(7)}, stmt.Vars)
+
+	_, err = Parse(newContext("/users?cursor=invalid"), testConfig)
+	assert.Error(t, err)
+	_, err = Parse(newContext("/users?sort=name&cursor="+cursor.Encode()), testConfig)
+	assert.NoError(t, err)
+	_, err = Parse(newContext("/users?sort=name,created_at&cursor="+cursor.Encode()), testConfig)
+	assert.Error(t, err)
+}
+
+func TestNextCursor(t *testing.T) {
+	p, err := Parse(newContext("/users?per_page=2"), testConfig)
+	assert.NoError(t, err)
+
+	rows := []user{{ID: 3, CreatedAt: 300}, {ID: 2, CreatedAt: 200}, {ID: 1, CreatedAt: 100}}
+	next, err := p.NextCursor(&rows)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	cursor, err := DecodeCursor(next)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(200), int64(2)}, cursor.Values)
+
+	next, err = p.NextCursor(&rows)
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+
+	_, err = p.NextCursor(rows)
+	assert.Error(t, err)
+}
+
+func TestNextCursorTableColumn(t *testing.T) {
+	p := Pagination{PerPage: 1, Sorts: []Sort{{Column: "users.created_at", Desc: true}, {Column: "`users`.`id`"}}}
+	rows := []user{{ID: 3, CreatedAt: 300}, {ID: 2, CreatedAt: 200}}
+	next, err := p.NextCursor(&rows)
+	assert.NoError(t, err)
+
+	cursor, err := DecodeCursor(next)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(300), int64(3)}, cursor.Values)
+}
+
+func TestCursorTime(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.FixedZone("JST", 9*60*60))
+	published := created.Add(-time.Hour)
+	p := Pagination{PerPage: 1, Sorts: []Sort{{Column: "created_at", Desc: true}, {Column: "published_at"}, {Column: "deleted_at"}, {Column: "id"}}}
+
+	rows := []post{{ID: 2, CreatedAt: created, PublishedAt: &published}, {ID: 1}}
+	next, err := p.NextCursor(&rows)
+	assert.NoError(t, err)
+
+	cursor, err := DecodeCursor(next)
+	assert.NoError(t, err)
+	if assert.Len(t, cursor.Values, 4) {
+		assert.True(t, created.Equal(cursor.Values[0].(time.Time)))
+		assert.True(t, published.Equal(cursor.Values[1].(time.Time)))
+		assert.Nil(t, cursor.Values[2])
+		assert.Equal(t, int64(2), cursor.Values[3])
+	}
+
+	_, err = DecodeCursor(Cursor{Values: []interface{}{map[string]string{"time": "yesterday"}}}.Encode())
+	assert.Error(t, err)
+	_, err = DecodeCursor(Cursor{Values: []interface{}{map[string]string{"date": "2024-05-01"}}}.Encode())
+	assert.Error(t, err)
+}
+
+func TestCursorScopeNull(t *testing.T) {
+	config := Config{
+		SortFields:     map[string]string{"id": "id", "published_at": "published_at"},
+		NullableFields: []string{"published_at"},
+	}
+	published := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		haveSort   string
+		haveValues []interface{}
+		wantSQL    string
+		wantVars   []interface{}
+	}{
+		{"-published_at", []interface{}{published, int64(7)},
+			"SELECT * FROM `posts` WHERE ((`published_at` < ? OR `published_at` IS NULL) OR (`published_at` = ? AND `id` > ?)) ORDER BY `published_at` DESC,`id` LIMIT 3",
+			[]interface{}{published, published, int64(7)}},
+		{"-published_at", []interface{}{nil, int64(7)},
+			"SELECT * FROM `posts` WHERE (`published_at` IS NULL AND `id` > ?) ORDER BY `published_at` DESC,`id` LIMIT 3",
+			[]interface{}{int64(7)}},
+		{"published_at", []interface{}{nil, int64(7)},
+			"SELECT * FROM `posts` WHERE (`published_at` IS NOT NULL OR (`published_at` IS NULL AND `id` > ?)) ORDER BY `published_at`,`id` LIMIT 3",
+			[]interface{}{int64(7)}},
+		{"published_at", []interface{}{published, int64(7)},
+			"SELECT * FROM `posts` WHERE (`published_at` > ? OR (`published_at` = ? AND `id` > ?)) ORDER BY `published_at`,`id` LIMIT 3",
+			[]interface{}{published, published, int64(7)}},
+	}
+
+	for _, tt := range tests {
+		cursor := Cursor{Values: tt.haveValues}
+		p, err := Parse(newContext("/posts?per_page=2&sort="+tt.haveSort+"&cursor="+cursor.Encode()), config)
+		assert.NoError(t, err)
+
+		stmt := openDryRunDB(t).Scopes(p.CursorScope()).Find(&[]post{}).Statement
+		assert.Equal(t, tt.wantSQL, stmt.SQL.String(), tt.haveSort)
+		assert.Equal(t, tt.wantVars, stmt.Vars, tt.haveSort)
+	}
+}
+
+func TestNewResponse(t *testing.T) {
+	c := newContext("/users?page=2&per_page=10")
+	p, err := Parse(c, testConfig)
+	assert.NoError(t, err)
+
+	response := NewResponse(c, p, []user{}, 35)
+	assert.Equal(t, int64(35), *response.Meta.Total)
+	assert.Equal(t, "/users?page=2&per_page=10", response.Links.Self)
+	assert.Equal(t, "/users?page=3&per_page=10", response.Links.Next)
+	assert.Equal(t, "/users?page=1&per_page=10", response.Links.Prev)
+
+	response = NewResponse(c, p, []user{}, 20)
+	assert.Empty(t, response.Links.Next)
+
+	// page * per_page overflows int on 32-bit platforms
+	p = Pagination{Page: math.MaxInt32 / 100, PerPage: 100}
+	response = NewResponse(c, p, []user{}, math.MaxInt64)
+	assert.NotEmpty(t, response.Links.Next)
+
+	response = NewCursorResponse(c, p, []user{}, "abc")
+	assert.Equal(t, "abc", response.Meta.NextCursor)
+	assert.Equal(t, "/users?cursor=abc&per_page=10", response.Links.Next)
+}
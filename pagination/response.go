@@ -0,0 +1,70 @@
+package pagination
+
+import (
+	"strconv"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// Response is the standard envelope of list endpoints
+type Response struct {
+	Data  interface{} `json:"data"`
+	Meta  Meta        `json:"meta"`
+	Links Links       `json:"links"`
+}
+
+// Meta ...
+type Meta struct {
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page"`
+	Total      *int64 `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Links are URLs of the current, next and previous pages
+type Links struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// NewResponse creates envelope of offset pagination with total count
+// Usage c.JSON(http.StatusOK, pagination.NewResponse(c, p, users, total))
+func NewResponse(c echo.Context, p Pagination, data interface{}, total int64) Response {
+	response := Response{
+		Data:  data,
+		Meta:  Meta{Page: p.Page, PerPage: p.PerPage, Total: &total},
+		Links: Links{Self: c.Request().URL.RequestURI()},
+	}
+	if int64(p.Page)*int64(p.PerPage) < total {
+		response.Links.Next = pageURL(c, QueryPage, strconv.Itoa(p.Page+1))
+	}
+	if p.Page > 1 {
+		response.Links.Prev = pageURL(c, QueryPage, strconv.Itoa(p.Page-1))
+	}
+	return response
+}
+
+// NewCursorResponse creates envelope of cursor pagination with next cursor returned by NextCursor
+func NewCursorResponse(c echo.Context, p Pagination, data interface{}, nextCursor string) Response {
+	response := Response{
+		Data:  data,
+		Meta:  Meta{PerPage: p.PerPage, NextCursor: nextCursor},
+		Links: Links{Self: c.Request().URL.RequestURI()},
+	}
+	if nextCursor != "" {
+		response.Links.Next = pageURL(c, QueryCursor, nextCursor)
+	}
+	return response
+}
+
+func pageURL(c echo.Context, key, value string) string {
+	u := *c.Request().URL
+	query := u.Query()
+	query.Set(key, value)
+	if key == QueryCursor {
+		query.Del(QueryPage)
+	}
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/rakutentech/go-echo-kit/errors/httperrors"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MiddlewareConfig defines the config for tracing middleware
+type MiddlewareConfig struct {
+	// Skipper defines a function to skip middleware
+	Skipper middleware.Skipper
+
+	// ServerName is http.server_name attribute
+	ServerName string
+
+	// TracerProvider (default: global provider)
+	TracerProvider trace.TracerProvider
+
+	// Propagators extract parent span from request headers like traceparent (default: global propagators)
+	Propagators propagation.TextMapPropagator
+}
+
+// DefaultMiddlewareConfig ...
+var DefaultMiddlewareConfig = MiddlewareConfig{
+	Skipper: middleware.DefaultSkipper,
+}
+
+// Middleware starts span per request with DefaultMiddlewareConfig
+func Middleware() echo.MiddlewareFunc {
+	return MiddlewareWithConfig(DefaultMiddlewareConfig)
+}
+
+// MiddlewareWithConfig starts span per request, which continues trace of W3C traceparent header.
+// Handlers get the span from c.Request().Context(), and trace ID is set to X-Trace-Id response header.
+// It adds trace_id and span_id of context to logger (see LogContextField)
+func MiddlewareWithConfig(config MiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultMiddlewareConfig.Skipper
+	}
+	registerLogContextField()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			provider := config.TracerProvider
+			if provider == nil {
+				provider = otel.GetTracerProvider()
+			}
+			propagators := config.Propagators
+			if propagators == nil {
+				propagators = otel.GetTextMapPropagator()
+			}
+
+			req := c.Request()
+			route := c.Path()
+			spanName := route
+			if spanName == "" {
+				spanName = "HTTP " + req.Method
+			}
+
+			ctx := propagators.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := provider.Tracer(InstrumentationName).Start(ctx, spanName,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(config.ServerName, route, req)...),
+				trace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", req)...),
+			)
+			defer span.End()
+
+			if span.SpanContext().IsValid() {
+				c.Response().Header().Set("X-Trace-Id", span.SpanContext().TraceID().String())
+			}
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			status := c.Response().Status
+			if err != nil {
+				span.RecordError(err)
+				// echo writes error response after middleware returns
+				if !c.Response().Committed {
+					status = httperrors.HTTPStatus(err)
+				}
+			}
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+			span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+			return err
+		}
+	}
+}
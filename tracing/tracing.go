@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rakutentech/go-echo-kit/logger"
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of tracer for kit spans
+const InstrumentationName = "github.com/rakutentech/go-echo-kit"
+
+// Exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config defines the config for tracer provider
+type Config struct {
+	// ServiceName is service.name of resource
+	ServiceName string
+
+	// Exporter is one of none, stdout and otlp (default: none)
+	Exporter string
+
+	// Endpoint is host:port of OTLP HTTP receiver (default: localhost:4318)
+	Endpoint string
+
+	// Insecure disables TLS of OTLP
+	Insecure bool
+
+	// SampleRatio samples root spans, and child spans follow their parents (default: 1)
+	SampleRatio float64
+}
+
+// ConfigFromConfig reads service_name, exporter, endpoint, insecure and sample_ratio from config
+func ConfigFromConfig(cfg *viper.Viper) Config {
+	config := Config{SampleRatio: 1}
+	if cfg == nil {
+		return config
+	}
+
+	config.ServiceName = cfg.GetString("service_name")
+	config.Exporter = strings.ToLower(cfg.GetString("exporter"))
+	config.Endpoint = cfg.GetString("endpoint")
+	config.Insecure = cfg.GetBool("insecure")
+	if cfg.IsSet("sample_ratio") {
+		config.SampleRatio = cfg.GetFloat64("sample_ratio")
+	}
+	return config
+}
+
+// NewProvider creates tracer provider with exporter in config, and sets it and W3C propagators as global
+// Call Shutdown of the provider to flush spans before exit
+func NewProvider(config Config) (*sdktrace.TracerProvider, error) {
+	exporter, err := newExporter(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewProviderWithExporter(config, exporter), nil
+}
+
+// NewProviderWithExporter creates tracer provider with given exporter (nil for none), and sets it as global.
+// It adds trace_id and span_id of context to logger (see LogContextField)
+func NewProviderWithExporter(config Config, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	registerLogContextField()
+
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName, _ = os.Hostname()
+	}
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider
+}
+
+func newExporter(config Config) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), options...)
+	}
+	return nil, fmt.Errorf("unknown exporter: %s", config.Exporter)
+}
+
+// Tracer returns kit tracer of global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// LogContextField returns trace_id and span_id of context for logger.AddContextField.
+// NewProvider and Middleware add it to logger
+func LogContextField(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ""
+	}
+	return "trace_id=" + spanContext.TraceID().String() + " span_id=" + spanContext.SpanID().String()
+}
+
+var registerOnce sync.Once
+
+// registerLogContextField adds LogContextField to logger once
+func registerLogContextField() {
+	registerOnce.Do(func() {
+		logger.AddContextField(LogContextField)
+	})
+}
@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	kiterrors "github.com/rakutentech/go-echo-kit/errors"
+	"github.com/rakutentech/go-echo-kit/errors/httperrors"
+	"github.com/rakutentech/go-echo-kit/logger"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func newTestProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return provider, exporter
+}
+
+func TestMiddleware(t *testing.T) {
+	provider, exporter := newTestProvider()
+	NewProviderWithExporter(Config{ServiceName: "test", SampleRatio: 1}, nil)
+
+	e := echo.New()
+	e.Use(MiddlewareWithConfig(MiddlewareConfig{TracerProvider: provider}))
+
+	var logFields string
+	e.GET("/users/:id", func(c echo.Context) error {
+		logFields = logger.ContextFields(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/error", func(c echo.Context) error {
+		return errors.New("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("traceparent", traceparent)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "/users/:id", spans[0].Name)
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rec.Header().Get("X-Trace-Id"))
+	assert.Equal(t, "trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id="+spans[0].SpanContext.SpanID().String(), logFields)
+
+	exporter.Reset()
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/error", nil))
+
+	spans = exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.False(t, spans[0].Parent.IsValid())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestMiddlewareErrorStatus(t *testing.T) {
+	provider, exporter := newTestProvider()
+
+	e := echo.New()
+	handled := 0
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		handled++
+		httperrors.HTTPErrorHandler()(err, c)
+	}
+	e.Use(MiddlewareWithConfig(MiddlewareConfig{TracerProvider: provider}))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return kiterrors.NewErrorWithMsg(kiterrors.ErrCodeNotExistInDB, "user 1 not found")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	assert.Equal(t, 1, handled)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes, semconv.HTTPStatusCodeKey.Int(http.StatusNotFound))
+}
+
+func TestLogContextField(t *testing.T) {
+	assert.Empty(t, LogContextField(context.Background()))
+}
+
+func TestNewProvider(t *testing.T) {
+	provider, err := NewProvider(Config{Exporter: ExporterStdout})
+	assert.NoError(t, err)
+	assert.NoError(t, provider.Shutdown(context.Background()))
+
+	_, err = NewProvider(Config{Exporter: "unknown"})
+	assert.Error(t, err)
+}